@@ -0,0 +1,186 @@
+package bendigo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// nodes and weights of 5-point Gauss-Legendre quadrature on [-1, 1]
+var gaussLegendreNodes = [5]float64{0, -0.5384693101056831, 0.5384693101056831, -0.9061798459386640, 0.9061798459386640}
+var gaussLegendreWeights = [5]float64{0.5688888888888889, 0.4786286704993665, 0.4786286704993665, 0.2369268850561891, 0.2369268850561891}
+
+// maxArcLenDepth limits the number of bisections during adaptive integration
+const maxArcLenDepth = 30
+
+// speedIntegral integrates the speed |spline'(t)| over [ta, tb] using Gauss-Legendre quadrature
+// precondition: ta and tb belong to the same segment
+func speedIntegral(spline Differentiable, ta, tb float64) float64 {
+	mid, half := (ta+tb)/2, (tb-ta)/2
+	sum := 0.
+	for i, x := range gaussLegendreNodes {
+		v := spline.Deriv(mid+half*x, 1)
+		if v != nil {
+			sum += gaussLegendreWeights[i] * v.Len()
+		}
+	}
+	return sum * half
+}
+
+// adaptiveSpeedIntegral bisects [ta, tb] until the integral is within tolerance
+func adaptiveSpeedIntegral(spline Differentiable, ta, tb float64, whole float64, tolerance float64, depth int) float64 {
+	tm := (ta + tb) / 2
+	left, right := speedIntegral(spline, ta, tm), speedIntegral(spline, tm, tb)
+	if depth >= maxArcLenDepth || math.Abs(left+right-whole) <= tolerance {
+		return left + right
+	}
+	return adaptiveSpeedIntegral(spline, ta, tm, left, tolerance/2, depth+1) +
+		adaptiveSpeedIntegral(spline, tm, tb, right, tolerance/2, depth+1)
+}
+
+// SegmentArcLen calculates the arc length of a segment up to given tolerance
+func SegmentArcLen(spline Differentiable, segmentNo int, tolerance float64) (l float64, err error) {
+	tstart, tend, err := SegmentTrange(spline.Knots(), segmentNo)
+	if err != nil {
+		return 0, err
+	}
+	return adaptiveSpeedIntegral(spline, tstart, tend, speedIntegral(spline, tstart, tend), tolerance, 0), nil
+}
+
+// ArcLen calculates the total arc length of the spline up to given tolerance
+func ArcLen(spline Differentiable, tolerance float64) float64 {
+	segmentCnt := spline.Knots().SegmentCnt()
+	l := 0.
+	for segmentNo := 0; segmentNo < segmentCnt; segmentNo++ {
+		sl, _ := SegmentArcLen(spline, segmentNo, tolerance/float64(segmentCnt))
+		l += sl
+	}
+	return l
+}
+
+// ArcLenInRange calculates the arc length between parameters tstart and tend up to given tolerance
+func ArcLenInRange(spline Differentiable, tstart, tend float64, tolerance float64) (l float64, err error) {
+	if tstart > tend {
+		return 0, fmt.Errorf("tstart %v greater than tend %v", tstart, tend)
+	}
+	knots := spline.Knots()
+	fromSegmentNo, _, err := knots.MapToSegment(tstart)
+	if err != nil {
+		return 0, err
+	}
+	toSegmentNo, _, err := knots.MapToSegment(tend)
+	if err != nil {
+		return 0, err
+	}
+
+	// integrate piecewise per segment, derivatives may be discontinuous at knots
+	for segmentNo := fromSegmentNo; segmentNo <= toSegmentNo; segmentNo++ {
+		ts, te, _ := SegmentTrange(knots, segmentNo)
+		ts, te = math.Max(ts, tstart), math.Min(te, tend)
+		if te > ts {
+			l += adaptiveSpeedIntegral(spline, ts, te, speedIntegral(spline, ts, te), tolerance, 0)
+		}
+	}
+	return l, nil
+}
+
+// ArcLenSpline reparameterizes a spline by arc length, i.e. At(s) moves along the spline at constant unit speed.
+// Parameter s is mapped to t by a precomputed lookup table, refined by newton iterations
+type ArcLenSpline struct {
+	spline    Differentiable
+	knots     Knots     // arc lengths at the knots of spline
+	ts        []float64 // lookup table: parameters t ...
+	ss        []float64 // ... and corresponding arc lengths s, both ascending
+	tolerance float64
+}
+
+// NewArcLenSpline creates an arc length parameterized wrapper, sampling each segment samplesPerSegment times
+func NewArcLenSpline(spline Differentiable, samplesPerSegment int, tolerance float64) *ArcLenSpline {
+	if samplesPerSegment < 1 {
+		samplesPerSegment = 1
+	}
+	knots := spline.Knots()
+	segmentCnt := knots.SegmentCnt()
+
+	sknots := make([]float64, 0, knots.KnotCnt())
+	ts := make([]float64, 0, segmentCnt*samplesPerSegment+1)
+	ss := make([]float64, 0, segmentCnt*samplesPerSegment+1)
+	if knots.KnotCnt() > 0 {
+		t0, _ := knots.Knot(0)
+		sknots = append(sknots, 0)
+		ts = append(ts, t0)
+		ss = append(ss, 0)
+	}
+	s := 0.
+	for segmentNo := 0; segmentNo < segmentCnt; segmentNo++ {
+		tstart, tend, _ := SegmentTrange(knots, segmentNo)
+		tprev := tstart
+		for j := 1; j <= samplesPerSegment; j++ {
+			t := tstart + float64(j)/float64(samplesPerSegment)*(tend-tstart)
+			s += adaptiveSpeedIntegral(spline, tprev, t, speedIntegral(spline, tprev, t), tolerance/float64(segmentCnt*samplesPerSegment), 0)
+			ts = append(ts, t)
+			ss = append(ss, s)
+			tprev = t
+		}
+		sknots = append(sknots, s)
+	}
+
+	return &ArcLenSpline{spline: spline, knots: NewNonUniformKnots(sknots), ts: ts, ss: ss, tolerance: tolerance}
+}
+
+func (sp *ArcLenSpline) Knots() Knots {
+	return sp.knots
+}
+
+// Len returns the total arc length
+func (sp *ArcLenSpline) Len() float64 {
+	if len(sp.ss) == 0 {
+		return 0
+	}
+	return sp.ss[len(sp.ss)-1]
+}
+
+// ParamAt maps arc length s to parameter t of the underlying spline
+func (sp *ArcLenSpline) ParamAt(s float64) (t float64, err error) {
+	if len(sp.ss) == 0 {
+		return 0, fmt.Errorf("empty spline, arc length %v can't be mapped", s)
+	}
+	if s < 0 || s > sp.Len() {
+		return 0, fmt.Errorf("arc length %v outside of range 0..%v", s, sp.Len())
+	}
+
+	// find table entry i with ss[i] <= s <= ss[i+1]
+	i := sort.SearchFloat64s(sp.ss, s)
+	if i == 0 || sp.ss[i] == s {
+		return sp.ts[i], nil
+	}
+	i--
+	tlo, thi := sp.ts[i], sp.ts[i+1]
+	if sp.ss[i+1] == sp.ss[i] {
+		return tlo, nil
+	}
+
+	// initial guess by linear interpolation, refined by newton iterations on f(t) = arclen(ts[i], t) - (s - ss[i])
+	t = tlo + (s-sp.ss[i])/(sp.ss[i+1]-sp.ss[i])*(thi-tlo)
+	for iter := 0; iter < 8; iter++ {
+		f := adaptiveSpeedIntegral(sp.spline, tlo, t, speedIntegral(sp.spline, tlo, t), sp.tolerance, 0) - (s - sp.ss[i])
+		if math.Abs(f) <= sp.tolerance {
+			break
+		}
+		v := sp.spline.Deriv(t, 1)
+		if v == nil || v.Len() == 0 {
+			break
+		}
+		t = math.Min(math.Max(t-f/v.Len(), tlo), thi)
+	}
+	return t, nil
+}
+
+// At calculates the point on the spline at arc length s
+func (sp *ArcLenSpline) At(s float64) Vec {
+	t, err := sp.ParamAt(s)
+	if err != nil {
+		return nil
+	}
+	return sp.spline.At(t)
+}
@@ -0,0 +1,41 @@
+package bendigo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestArcLen(t *testing.T) {
+	cc := circle2d{2}
+	assert.InDelta(t, 6*cc.r, ArcLen(cc, delta), 1e-8, "arc length of circle arc with angle 6")
+	sl, err := SegmentArcLen(cc, 3, delta)
+	assert.Nil(t, err)
+	assert.InDelta(t, cc.r, sl, 1e-8, "arc length of segment")
+	_, err = SegmentArcLen(cc, 6, delta)
+	assert.NotNil(t, err, "segment doesn't exist")
+
+	l, err := ArcLenInRange(cc, 0.5, 2.25, delta)
+	assert.Nil(t, err)
+	assert.InDelta(t, 1.75*cc.r, l, 1e-8, "arc length across knots")
+	_, err = ArcLenInRange(cc, 2, 1, delta)
+	assert.NotNil(t, err, "tstart greater than tend")
+
+	hx := helix{r: 2, h: 1}
+	assert.InDelta(t, 6*math.Sqrt(5), ArcLen(hx, delta), 1e-8, "arc length of helix")
+}
+
+func TestArcLenSpline(t *testing.T) {
+	cc := circle2d{2}
+	alsp := NewArcLenSpline(cc, 4, delta)
+	assert.InDelta(t, 6*cc.r, alsp.Len(), 1e-8, "total arc length")
+	assert.Equal(t, cc.Knots().KnotCnt(), alsp.Knots().KnotCnt(), "same number of knots")
+	sk, _ := alsp.Knots().Knot(2)
+	assert.InDelta(t, 2*cc.r, sk, 1e-8, "knot at arc length")
+
+	for _, s := range []float64{0, 0.1, 1, 3.3, 7.9, 12} {
+		assert.InDeltaSlice(t, cc.At(s/cc.r), alsp.At(s), 1e-8, "point at arc length %v", s)
+	}
+	assert.Nil(t, alsp.At(-1), "arc length out of range")
+	assert.Nil(t, alsp.At(12.5), "arc length out of range")
+}
@@ -0,0 +1,79 @@
+package bendigo
+
+import "math"
+
+// BBox is an axis-aligned bounding box
+type BBox struct {
+	Min, Max Vec
+}
+
+// NewBBox creates the smallest bounding box containing all points, nil if no points are given
+func NewBBox(points ...Vec) *BBox {
+	if len(points) == 0 {
+		return nil
+	}
+	bb := &BBox{Min: points[0].Scale(1), Max: points[0].Scale(1)}
+	for _, p := range points[1:] {
+		bb.Extend(p)
+	}
+	return bb
+}
+
+func (bb *BBox) Dim() int {
+	return bb.Min.Dim()
+}
+
+// Extend enlarges the bounding box to contain point p
+func (bb *BBox) Extend(p Vec) {
+	for d := 0; d < bb.Dim(); d++ {
+		bb.Min[d] = math.Min(bb.Min[d], p[d])
+		bb.Max[d] = math.Max(bb.Max[d], p[d])
+	}
+}
+
+// Union returns the smallest bounding box containing both boxes, nil boxes are treated as empty
+func (bb *BBox) Union(other *BBox) *BBox {
+	if bb == nil {
+		return other
+	} else if other == nil {
+		return bb
+	}
+	return NewBBox(bb.Min, bb.Max, other.Min, other.Max)
+}
+
+func (bb *BBox) Size() Vec {
+	return bb.Max.Sub(bb.Min)
+}
+
+func (bb *BBox) Contains(p Vec) bool {
+	for d := 0; d < bb.Dim(); d++ {
+		if p[d] < bb.Min[d] || p[d] > bb.Max[d] {
+			return false
+		}
+	}
+	return true
+}
+
+func (bb *BBox) Intersects(other *BBox) bool {
+	for d := 0; d < bb.Dim(); d++ {
+		if other.Max[d] < bb.Min[d] || other.Min[d] > bb.Max[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// Dist calculates the distance of point p to the bounding box, 0 if p is inside
+func (bb *BBox) Dist(p Vec) float64 {
+	dist := 0.
+	for d := 0; d < bb.Dim(); d++ {
+		var dd float64
+		if p[d] < bb.Min[d] {
+			dd = bb.Min[d] - p[d]
+		} else if p[d] > bb.Max[d] {
+			dd = p[d] - bb.Max[d]
+		}
+		dist += dd * dd
+	}
+	return math.Sqrt(dist)
+}
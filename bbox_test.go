@@ -0,0 +1,29 @@
+package bendigo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestBBox(t *testing.T) {
+	assert.Nil(t, NewBBox(), "no points, no box")
+
+	bb := NewBBox(NewVec(1, 2), NewVec(-1, 3), NewVec(0, 0))
+	assert.Equal(t, NewVec(-1, 0), bb.Min, "minimum")
+	assert.Equal(t, NewVec(1, 3), bb.Max, "maximum")
+	assert.Equal(t, NewVec(2, 3), bb.Size(), "size")
+	assert.True(t, bb.Contains(NewVec(0, 1)), "point inside")
+	assert.False(t, bb.Contains(NewVec(2, 1)), "point outside")
+	assert.Equal(t, 0., bb.Dist(NewVec(0, 1)), "point inside has distance 0")
+	assert.InDelta(t, math.Sqrt2, bb.Dist(NewVec(2, 4)), delta, "distance to corner")
+
+	other := NewBBox(NewVec(2, 2), NewVec(3, 3))
+	assert.False(t, bb.Intersects(other), "disjoint boxes")
+	union := bb.Union(other)
+	assert.Equal(t, NewVec(-1, 0), union.Min, "minimum of union")
+	assert.Equal(t, NewVec(3, 3), union.Max, "maximum of union")
+	assert.True(t, union.Intersects(other), "overlapping boxes")
+	var empty *BBox
+	assert.Equal(t, bb, empty.Union(bb), "union with empty box")
+}
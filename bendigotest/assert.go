@@ -0,0 +1,33 @@
+// Package bendigotest provides asserts shared by the tests of the spline packages
+package bendigotest
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"testing"
+)
+
+// Delta is the tolerance of the asserts
+const Delta = 1e-10
+
+func AssertVecInDelta(t *testing.T, expected bendigo.Vec, actual bendigo.Vec, msg string) {
+	assert.Equal(t, expected.Dim(), actual.Dim(), msg+", dimensions differ")
+	for d := 0; d < expected.Dim(); d++ {
+		assert.InDeltaf(t, expected[d], actual[d], Delta, msg+", at dim = %v", d)
+	}
+}
+
+// AssertSplinesEqualInRange compares both splines at sampleCnt + 1 evenly distributed parameters from tstart to tend
+func AssertSplinesEqualInRange(t *testing.T, spline0 bendigo.Spline, spline1 bendigo.Spline, tstart, tend float64, sampleCnt int) {
+	for i := 0; i <= sampleCnt; i++ {
+		at := tstart + float64(i)/float64(sampleCnt)*(tend-tstart)
+		v0, v1 := spline0.At(at), spline1.At(at)
+		AssertVecInDelta(t, v0, v1, fmt.Sprintf("spline0.At(%v) = %v != spline1.At(%v) = %v", at, v0, at, v1))
+	}
+}
+
+// AssertSplinesEqual compares both splines over the domain of spline0, see AssertSplinesEqualInRange
+func AssertSplinesEqual(t *testing.T, spline0 bendigo.Spline, spline1 bendigo.Spline, sampleCnt int) {
+	AssertSplinesEqualInRange(t, spline0, spline1, spline0.Knots().Tstart(), spline0.Knots().Tend(), sampleCnt)
+}
@@ -0,0 +1,67 @@
+package bendigo
+
+// MaxLinaxDepth limits the number of recursive subdivisions of a bezier curve during linear approximation
+const MaxLinaxDepth = 30
+
+// DeCasteljau evaluates the bezier curve of arbitrary degree given by controls at local parameter u
+func DeCasteljau(controls []Vec, u float64) Vec {
+	work := make([]Vec, len(controls))
+	copy(work, controls)
+	for r := len(work) - 1; r > 0; r-- {
+		for i := 0; i < r; i++ {
+			work[i] = work[i].Scale(1 - u).Add(work[i+1].Scale(u))
+		}
+	}
+	return work[0]
+}
+
+// SplitBezier subdivides the bezier curve given by controls at local parameter u using De Casteljau algorithm
+func SplitBezier(controls []Vec, u float64) (left, right []Vec) {
+	n := len(controls)
+	left, right = make([]Vec, n), make([]Vec, n)
+	work := make([]Vec, n)
+	copy(work, controls)
+	for r := 0; r < n; r++ {
+		left[r], right[n-1-r] = work[0], work[n-1-r]
+		for i := 0; i < n-1-r; i++ {
+			work[i] = work[i].Scale(1 - u).Add(work[i+1].Scale(u))
+		}
+	}
+	return left, right
+}
+
+// HodographControls returns the controls of the derivative of the bezier curve given by controls,
+// which has one control less: n * (b[i+1] - b[i]). Returns nil for a single control
+func HodographControls(controls []Vec) []Vec {
+	n := len(controls) - 1
+	if n <= 0 {
+		return nil
+	}
+	hodograph := make([]Vec, n)
+	for i := 0; i < n; i++ {
+		hodograph[i] = controls[i+1].Sub(controls[i]).Scale(float64(n))
+	}
+	return hodograph
+}
+
+// IsFlatBezier checks if all inner controls are within maxDist of the line between start and end control.
+// The curve lies within the convex hull of its controls, so it deviates at most maxDist from that line
+func IsFlatBezier(controls []Vec, maxDist float64) bool {
+	n := len(controls)
+	if n <= 2 {
+		return true
+	}
+	start := controls[0]
+	chord := controls[n-1].Sub(start)
+	for i := 1; i < n-1; i++ {
+		v := controls[i].Sub(start)
+		if chord.Len() == 0 { // start equals end, use distance to start instead
+			if v.Len() > maxDist {
+				return false
+			}
+		} else if v.ProjectedVecDist(chord) > maxDist {
+			return false
+		}
+	}
+	return true
+}
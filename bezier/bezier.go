@@ -0,0 +1,241 @@
+package bezier
+
+import (
+	"fmt"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/cubic"
+)
+
+// continuityTolerance is the maximum distance of end and start of adjacent segments to be connected
+const continuityTolerance = 1e-9
+
+// BezierBuilder builds splines of consecutive bezier segments, each of arbitrary degree.
+// Segment no. i spans the knots i and i+1, continuity between segments is up to the caller
+type BezierBuilder struct {
+	knots    bendigo.Knots
+	segments []*Segment
+}
+
+// NewBezierBuilder creates a builder, tknots: nil = uniform, else one knot more than segments
+func NewBezierBuilder(tknots []float64, segments ...*Segment) *BezierBuilder {
+	var knots bendigo.Knots
+	knotCnt := len(segments) + 1
+	if len(segments) == 0 {
+		knotCnt = 0
+	}
+	if tknots == nil {
+		knots = bendigo.NewUniformKnots(knotCnt)
+	} else {
+		if len(tknots) != knotCnt {
+			panic("knots must have length of segments + 1")
+		}
+		knots = bendigo.NewNonUniformKnots(tknots)
+	}
+	return &BezierBuilder{knots: knots, segments: segments}
+}
+
+// NewBezierBuilderFromCubic converts a cubic bezier builder, each segment has degree 3
+func NewBezierBuilderFromCubic(cb *cubic.BezierVertBuilder) *BezierBuilder {
+	knots := cb.Knots()
+	segments := make([]*Segment, knots.SegmentCnt())
+	for i := range segments {
+		start, end := cb.BezierVertex(i), cb.BezierVertex(i+1)
+		if end == nil {
+			end = cb.BezierVertex(0) // closing segment
+		}
+		segments[i] = NewSegment(start.Loc(), start.ExitAsAbsolute(), end.EntryAsAbsolute(), end.Loc())
+	}
+	return NewBezierBuilder(knots.External(), segments...)
+}
+
+func (sb *BezierBuilder) Knots() bendigo.Knots {
+	return sb.knots
+}
+
+func (sb *BezierBuilder) Dim() int {
+	if len(sb.segments) == 0 {
+		return 0
+	} else {
+		return sb.segments[0].Dim()
+	}
+}
+
+// Segment returns the segment with given no.
+func (sb *BezierBuilder) Segment(segmentNo int) *Segment {
+	if segmentNo < 0 || segmentNo >= len(sb.segments) {
+		return nil
+	} else {
+		return sb.segments[segmentNo]
+	}
+}
+
+func (sb *BezierBuilder) UpdateSegment(segmentNo int, segment *Segment) (err error) {
+	if segmentNo < 0 || segmentNo >= len(sb.segments) {
+		return fmt.Errorf("segment with no. %v doesn't exist", segmentNo)
+	}
+	sb.segments[segmentNo] = segment
+	return nil
+}
+
+// MaxDegree returns the highest degree of all segments
+func (sb *BezierBuilder) MaxDegree() int {
+	maxDegree := 0
+	for _, sg := range sb.segments {
+		if sg.Degree() > maxDegree {
+			maxDegree = sg.Degree()
+		}
+	}
+	return maxDegree
+}
+
+// ElevateTo creates a builder of the same shape where all segments have the given degree,
+// which must not be lower than MaxDegree
+func (sb *BezierBuilder) ElevateTo(degree int) (*BezierBuilder, error) {
+	segments := make([]*Segment, len(sb.segments))
+	for i, sg := range sb.segments {
+		elevated, err := sg.ElevateTo(degree)
+		if err != nil {
+			return nil, err
+		}
+		segments[i] = elevated
+	}
+	return NewBezierBuilder(sb.knots.External(), segments...), nil
+}
+
+// ReduceTo creates a builder where all segments of higher degree are reduced to the given degree.
+// Start and end points of the segments are kept, inner controls are approximated (see Segment.Reduce)
+func (sb *BezierBuilder) ReduceTo(degree int) (*BezierBuilder, error) {
+	if degree < 1 {
+		return nil, fmt.Errorf("degree %v can't be reduced to, at least 1 required", degree)
+	}
+	segments := make([]*Segment, len(sb.segments))
+	for i, sg := range sb.segments {
+		for sg.Degree() > degree {
+			var err error
+			sg, err = sg.Reduce()
+			if err != nil {
+				return nil, err
+			}
+		}
+		segments[i] = sg
+	}
+	return NewBezierBuilder(sb.knots.External(), segments...), nil
+}
+
+// Cubic converts into a cubic bezier builder with the same knots. Segments of lower degree are elevated exactly,
+// segments of higher degree are reduced approximately. The unused entry of the first and exit of the last vertex
+// mirror the controls of the adjacent segment
+func (sb *BezierBuilder) Cubic() (*cubic.BezierVertBuilder, error) {
+	segmCnt := len(sb.segments)
+	if segmCnt == 0 {
+		return cubic.NewBezierVertBuilder(sb.knots.External()), nil
+	}
+
+	cubics := make([]*Segment, segmCnt)
+	for i, sg := range sb.segments {
+		var err error
+		if sg.Degree() <= 3 {
+			sg, err = sg.ElevateTo(3)
+		} else {
+			for sg.Degree() > 3 && err == nil {
+				sg, err = sg.Reduce()
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		cubics[i] = sg
+	}
+
+	vertices := make([]*cubic.EnexVertex, segmCnt+1)
+	start := cubics[0].controls[0]
+	vertices[0] = cubic.NewBezierVertex(start, start.InvertInPoint(cubics[0].controls[1]), cubics[0].controls[1])
+	for i := 1; i < segmCnt; i++ {
+		loc := cubics[i].controls[0]
+		if loc.Sub(cubics[i-1].controls[3]).Len() > continuityTolerance {
+			return nil, fmt.Errorf("segments %v and %v are not connected", i-1, i)
+		}
+		vertices[i] = cubic.NewBezierVertex(loc, cubics[i-1].controls[2], cubics[i].controls[1])
+	}
+	end := cubics[segmCnt-1].controls[3]
+	vertices[segmCnt] = cubic.NewBezierVertex(end, cubics[segmCnt-1].controls[2], end.InvertInPoint(cubics[segmCnt-1].controls[2]))
+
+	return cubic.NewBezierVertBuilder(sb.knots.External(), vertices...), nil
+}
+
+func (sb *BezierBuilder) Spline() bendigo.Spline {
+	return sb.BezierSpline()
+}
+
+func (sb *BezierBuilder) BezierSpline() *BezierSpline {
+	segments := make([]*Segment, len(sb.segments))
+	copy(segments, sb.segments)
+	return &BezierSpline{knots: sb.knots, segments: segments}
+}
+
+// LinApproximate subdivides each segment adaptively until its controls are within linaxParams.MaxDist of a line
+func (sb *BezierBuilder) LinApproximate(fromSegmentNo, toSegmentNo int, consumer bendigo.LineConsumer, linaxParams *bendigo.LinaxParams) {
+	var subdivide func(segmentNo int, ts, te float64, sg *Segment, depth int)
+	subdivide = func(segmentNo int, ts, te float64, sg *Segment, depth int) {
+		if depth >= bendigo.MaxLinaxDepth || sg.isFlat(linaxParams.MaxDist) {
+			consumer.ConsumeLine(segmentNo, ts, te, sg.Start(), sg.End())
+		} else {
+			tm := (ts + te) / 2
+			left, right := sg.Split(0.5)
+			subdivide(segmentNo, ts, tm, left, depth+1)
+			subdivide(segmentNo, tm, te, right, depth+1)
+		}
+	}
+
+	for segmentNo := fromSegmentNo; segmentNo <= toSegmentNo; segmentNo++ {
+		tstart, tend, err := bendigo.SegmentTrange(sb.knots, segmentNo)
+		if err == nil { // ignore nonexistent segments
+			subdivide(segmentNo, tstart, tend, sb.segments[segmentNo], 0)
+		}
+	}
+}
+
+func (sb *BezierBuilder) LinaxSpline(linaxParams *bendigo.LinaxParams) *bendigo.LinaxSpline {
+	return bendigo.BuildLinaxSpline(sb, linaxParams)
+}
+
+// BezierSpline evaluates consecutive bezier segments of arbitrary degree
+type BezierSpline struct {
+	knots    bendigo.Knots
+	segments []*Segment
+}
+
+func (sp *BezierSpline) Knots() bendigo.Knots {
+	return sp.knots
+}
+
+func (sp *BezierSpline) Dim() int {
+	if len(sp.segments) == 0 {
+		return 0
+	}
+	return sp.segments[0].Dim()
+}
+
+func (sp *BezierSpline) At(t float64) bendigo.Vec {
+	if len(sp.segments) == 0 {
+		return nil
+	}
+
+	segmentNo, u, err := sp.knots.MapToSegment(t)
+	if err != nil {
+		return nil
+	}
+	return sp.segments[segmentNo].At(u)
+}
+
+func (sp *BezierSpline) Deriv(t float64, order int) bendigo.Vec {
+	if len(sp.segments) == 0 || order < 0 {
+		return nil
+	}
+
+	segmentNo, u, err := sp.knots.MapToSegment(t)
+	if err != nil {
+		return nil
+	}
+	return sp.segments[segmentNo].Deriv(u, order).Scale(bendigo.DerivScale(sp.knots, segmentNo, order))
+}
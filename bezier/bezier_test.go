@@ -0,0 +1,105 @@
+package bezier
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"github.com/walpod/bendigo/cubic"
+	"testing"
+)
+
+// createMixed connects a quadratic, a linear and a quintic segment
+func createMixed(tknots []float64) *BezierBuilder {
+	quintic := createQuintic().Controls()
+	shift := bendigo.NewVec(3, 0)
+	for i := range quintic {
+		quintic[i] = quintic[i].Add(shift)
+	}
+	return NewBezierBuilder(tknots,
+		createQuadratic(),
+		NewSegment(bendigo.NewVec(2, 0), bendigo.NewVec(3, 0)),
+		NewSegment(quintic...),
+	)
+}
+
+func TestBezierSpline(t *testing.T) {
+	sb := createMixed(nil)
+	assert.Equal(t, 4, sb.Knots().KnotCnt())
+	assert.Equal(t, 5, sb.MaxDegree())
+	spline := sb.BezierSpline()
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 1), spline.At(0.5), "quadratic")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(2.5, 0), spline.At(1.5), "line")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(8, 1), spline.At(3), "end")
+	assert.Nil(t, spline.At(3.5))
+
+	sb = createMixed([]float64{0, 2, 3, 7})
+	spline = sb.BezierSpline()
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 1), spline.At(1), "non-uniform quadratic")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 0), spline.Deriv(2.5, 1), "non-uniform velocity of line")
+	bendigotest.AssertVecInDelta(t, createQuintic().Deriv(0.5, 2).Scale(1./16), spline.Deriv(5, 2), "non-uniform acceleration")
+}
+
+func TestBezierBuilder_ElevateReduce(t *testing.T) {
+	sb := createMixed([]float64{0, 2, 3, 7})
+	elevated, err := sb.ElevateTo(6)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, elevated.Segment(1).Degree())
+	bendigotest.AssertSplinesEqual(t, sb.Spline(), elevated.Spline(), 70)
+
+	_, err = sb.ElevateTo(4)
+	assert.NotNil(t, err)
+
+	reduced, err := elevated.ReduceTo(5)
+	assert.Nil(t, err)
+	bendigotest.AssertSplinesEqual(t, sb.Spline(), reduced.Spline(), 70)
+}
+
+func TestBezierBuilder_Cubic(t *testing.T) {
+	cb := cubic.NewBezierVertBuilder([]float64{0, 1, 3},
+		cubic.NewBezierVertex(bendigo.NewVec(0, 0), nil, bendigo.NewVec(0, 1)),
+		cubic.NewBezierVertex(bendigo.NewVec(1, 1), bendigo.NewVec(1, 0), bendigo.NewVec(1, 2)),
+		cubic.NewBezierVertex(bendigo.NewVec(2, 2), bendigo.NewVec(2, 1), nil),
+	)
+	sb := NewBezierBuilderFromCubic(cb)
+	assert.Equal(t, 3, sb.MaxDegree())
+	bendigotest.AssertSplinesEqual(t, cb.Spline(), sb.Spline(), 30)
+
+	back, err := sb.Cubic()
+	assert.Nil(t, err)
+	bendigotest.AssertSplinesEqual(t, cb.Spline(), back.Spline(), 30)
+
+	// lower degrees are converted exactly
+	mixed := NewBezierBuilder(nil, createQuadratic(), NewSegment(bendigo.NewVec(2, 0), bendigo.NewVec(3, 0)))
+	cbm, err := mixed.Cubic()
+	assert.Nil(t, err)
+	bendigotest.AssertSplinesEqual(t, mixed.Spline(), cbm.Spline(), 20)
+
+	// higher degrees keep the knots
+	cbm, err = createMixed(nil).Cubic()
+	assert.Nil(t, err)
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(8, 1), cbm.Spline().At(3), "end of reduced quintic")
+
+	_, err = NewBezierBuilder(nil, createQuadratic(), createQuadratic()).Cubic()
+	assert.NotNil(t, err, "disconnected segments")
+}
+
+func TestBezierBuilder_LinApproximate(t *testing.T) {
+	sb := createMixed(nil)
+	maxDist := 0.01
+	linax := sb.LinaxSpline(bendigo.NewLinaxParams(maxDist))
+	lines := linax.Lines()
+	spline := sb.Spline()
+	for i, line := range lines {
+		bendigotest.AssertVecInDelta(t, spline.At(line.Tstart), line.Pstart, "line start on spline")
+		tm := (line.Tstart + line.Tend) / 2
+		assert.LessOrEqual(t, spline.At(tm).Sub(line.Pstart.Add(line.Pend).Scale(0.5)).Len(), 2*maxDist)
+		if i > 0 {
+			assert.Equal(t, lines[i-1].Tend, line.Tstart)
+		}
+	}
+
+	// straight line is approximated by a single line
+	collector := bendigo.NewLineToSliceCollector()
+	sb.LinApproximate(1, 1, collector, bendigo.NewLinaxParams(maxDist))
+	assert.Equal(t, 1, len(collector.Lines))
+}
@@ -0,0 +1,159 @@
+package bezier
+
+import (
+	"errors"
+	"github.com/walpod/bendigo"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Segment is a bezier curve of arbitrary degree on the local parameter range [0,1],
+// the degree is the number of controls - 1
+type Segment struct {
+	controls []bendigo.Vec
+}
+
+func NewSegment(controls ...bendigo.Vec) *Segment {
+	if len(controls) == 0 {
+		panic("segment needs at least one control")
+	}
+	return &Segment{controls: controls}
+}
+
+func (sg *Segment) Degree() int {
+	return len(sg.controls) - 1
+}
+
+func (sg *Segment) Dim() int {
+	return sg.controls[0].Dim()
+}
+
+// Controls returns a copy of the controls
+func (sg *Segment) Controls() []bendigo.Vec {
+	controls := make([]bendigo.Vec, len(sg.controls))
+	copy(controls, sg.controls)
+	return controls
+}
+
+func (sg *Segment) Start() bendigo.Vec {
+	return sg.controls[0]
+}
+
+func (sg *Segment) End() bendigo.Vec {
+	return sg.controls[len(sg.controls)-1]
+}
+
+// At calculates the point at local parameter u using De Casteljau algorithm
+func (sg *Segment) At(u float64) bendigo.Vec {
+	return bendigo.DeCasteljau(sg.controls, u)
+}
+
+// Hodograph returns the derivative as bezier segment of degree - 1, the derivative of a point is the zero point
+func (sg *Segment) Hodograph() *Segment {
+	if sg.Degree() == 0 {
+		return NewSegment(bendigo.NewZeroVec(sg.Dim()))
+	}
+	return NewSegment(bendigo.HodographControls(sg.controls)...)
+}
+
+// Deriv calculates the derivative of given order with respect to local parameter u
+func (sg *Segment) Deriv(u float64, order int) bendigo.Vec {
+	if order < 0 {
+		return nil
+	}
+	deriv := sg
+	for k := 0; k < order; k++ {
+		deriv = deriv.Hodograph()
+	}
+	return deriv.At(u)
+}
+
+// Split subdivides the segment at local parameter u using De Casteljau algorithm
+func (sg *Segment) Split(u float64) (left, right *Segment) {
+	lc, rc := bendigo.SplitBezier(sg.controls, u)
+	return NewSegment(lc...), NewSegment(rc...)
+}
+
+// Elevate returns the same curve as segment of degree + 1
+func (sg *Segment) Elevate() *Segment {
+	n := sg.Degree() + 1
+	controls := make([]bendigo.Vec, n+1)
+	controls[0], controls[n] = sg.controls[0], sg.controls[n-1]
+	for i := 1; i < n; i++ {
+		a := float64(i) / float64(n)
+		controls[i] = sg.controls[i-1].Scale(a).Add(sg.controls[i].Scale(1 - a))
+	}
+	return NewSegment(controls...)
+}
+
+// ElevateTo returns the same curve as segment of given degree, which must not be lower than the current one
+func (sg *Segment) ElevateTo(degree int) (*Segment, error) {
+	if degree < sg.Degree() {
+		return nil, errors.New("degree can't be lowered by elevation, use Reduce instead")
+	}
+	elevated := sg
+	for elevated.Degree() < degree {
+		elevated = elevated.Elevate()
+	}
+	return elevated, nil
+}
+
+// Reduce returns a segment of degree - 1 with the same start and end point, the inner controls are the least
+// squares solution of elevating them back to the original controls. The result is exact if the segment is
+// the elevation of a lower degree curve, else an approximation
+func (sg *Segment) Reduce() (*Segment, error) {
+	n := sg.Degree()
+	if n < 2 {
+		return nil, errors.New("degree must be at least 2 to be reduced")
+	}
+	m := n - 1
+	controls := make([]bendigo.Vec, m+1)
+	controls[0], controls[m] = sg.controls[0], sg.controls[n]
+	if m == 1 {
+		return NewSegment(controls...), nil
+	}
+
+	// elevation: P_i = i/n * Q_{i-1} + (1 - i/n) * Q_i, unknowns are Q_1 ... Q_{m-1} for equations i = 1 ... n-1
+	unknowns := m - 1
+	a := mat.NewDense(n-1, unknowns, nil)
+	for i := 1; i < n; i++ {
+		fac := float64(i) / float64(n)
+		if i-1 >= 1 {
+			a.Set(i-1, i-2, fac)
+		}
+		if i <= unknowns {
+			a.Set(i-1, i-1, 1-fac)
+		}
+	}
+
+	dim := sg.Dim()
+	for q := 1; q < m; q++ {
+		controls[q] = bendigo.NewZeroVec(dim)
+	}
+	for d := 0; d < dim; d++ {
+		b := mat.NewVecDense(n-1, nil)
+		for i := 1; i < n; i++ {
+			fac := float64(i) / float64(n)
+			rhs := sg.controls[i][d]
+			if i == 1 {
+				rhs -= fac * controls[0][d]
+			}
+			if i == n-1 {
+				rhs -= (1 - fac) * controls[m][d]
+			}
+			b.SetVec(i-1, rhs)
+		}
+		var x mat.VecDense
+		if err := x.SolveVec(a, b); err != nil {
+			return nil, err
+		}
+		for q := 1; q < m; q++ {
+			controls[q][d] = x.AtVec(q - 1)
+		}
+	}
+	return NewSegment(controls...), nil
+}
+
+// isFlat checks if all inner controls are within maxDist of the line between start and end control
+func (sg *Segment) isFlat(maxDist float64) bool {
+	return bendigo.IsFlatBezier(sg.controls, maxDist)
+}
@@ -0,0 +1,84 @@
+package bezier
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"testing"
+)
+
+func AssertSegmentsEqual(t *testing.T, sg0, sg1 *Segment, msg string) {
+	for i := 0; i <= 20; i++ {
+		u := float64(i) / 20
+		bendigotest.AssertVecInDelta(t, sg0.At(u), sg1.At(u), msg)
+	}
+}
+
+func createQuadratic() *Segment {
+	return NewSegment(bendigo.NewVec(0, 0), bendigo.NewVec(1, 2), bendigo.NewVec(2, 0))
+}
+
+func createQuintic() *Segment {
+	return NewSegment(bendigo.NewVec(0, 0), bendigo.NewVec(1, 2), bendigo.NewVec(2, -1),
+		bendigo.NewVec(3, 3), bendigo.NewVec(4, 0), bendigo.NewVec(5, 1))
+}
+
+func TestSegment_At(t *testing.T) {
+	sg := createQuadratic()
+	assert.Equal(t, 2, sg.Degree())
+	for i := 0; i <= 10; i++ {
+		u := float64(i) / 10
+		expected := bendigo.NewVec(2*u, 4*u*(1-u))
+		bendigotest.AssertVecInDelta(t, expected, sg.At(u), "quadratic")
+	}
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(5, 1), createQuintic().At(1), "end of quintic")
+}
+
+func TestSegment_Deriv(t *testing.T) {
+	sg := createQuadratic()
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(2, 4-8*0.3), sg.Deriv(0.3, 1), "velocity")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, -8), sg.Deriv(0.3, 2), "acceleration")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), sg.Deriv(0.3, 3), "jerk")
+	assert.Nil(t, sg.Deriv(0.3, -1))
+}
+
+func TestSegment_Split(t *testing.T) {
+	sg := createQuintic()
+	left, right := sg.Split(0.4)
+	for i := 0; i <= 10; i++ {
+		u := float64(i) / 10
+		bendigotest.AssertVecInDelta(t, sg.At(0.4*u), left.At(u), "left")
+		bendigotest.AssertVecInDelta(t, sg.At(0.4+0.6*u), right.At(u), "right")
+	}
+}
+
+func TestSegment_ElevateReduce(t *testing.T) {
+	sg := createQuadratic()
+	elevated, err := sg.ElevateTo(5)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, elevated.Degree())
+	AssertSegmentsEqual(t, sg, elevated, "elevation keeps shape")
+
+	_, err = elevated.ElevateTo(3)
+	assert.NotNil(t, err)
+
+	// reduction of an elevated curve is exact
+	reduced := elevated
+	for reduced.Degree() > 2 {
+		reduced, err = reduced.Reduce()
+		assert.Nil(t, err)
+	}
+	for i, c := range sg.Controls() {
+		bendigotest.AssertVecInDelta(t, c, reduced.Controls()[i], "reduced controls")
+	}
+
+	// reduction of a genuine quintic keeps the end points
+	reduced, err = createQuintic().Reduce()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, reduced.Degree())
+	bendigotest.AssertVecInDelta(t, createQuintic().Start(), reduced.Start(), "start")
+	bendigotest.AssertVecInDelta(t, createQuintic().End(), reduced.End(), "end")
+
+	_, err = NewSegment(bendigo.NewVec(0, 0), bendigo.NewVec(1, 1)).Reduce()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,43 @@
+package bendigo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeCasteljau(t *testing.T) {
+	controls := []Vec{NewVec(0, 0), NewVec(1, 2), NewVec(3, 2), NewVec(4, 0)}
+	assert.Equal(t, NewVec(0, 0), DeCasteljau(controls, 0), "start")
+	assert.Equal(t, NewVec(4, 0), DeCasteljau(controls, 1), "end")
+	assert.InDeltaSlice(t, NewVec(2, 1.5), DeCasteljau(controls, 0.5), delta, "middle")
+	assert.Equal(t, NewVec(1, 1), DeCasteljau([]Vec{NewVec(1, 1)}, 0.3), "single control is constant")
+}
+
+func TestSplitBezier(t *testing.T) {
+	controls := []Vec{NewVec(0, 0), NewVec(1, 2), NewVec(3, 2), NewVec(4, 0)}
+	u := 0.3
+	left, right := SplitBezier(controls, u)
+	assert.Len(t, left, 4, "same degree as original")
+	assert.Equal(t, controls[0], left[0], "left starts at start")
+	assert.Equal(t, controls[3], right[3], "right ends at end")
+	assert.InDeltaSlice(t, DeCasteljau(controls, u), left[3], delta, "left ends at split point")
+	assert.InDeltaSlice(t, DeCasteljau(controls, u), right[0], delta, "right starts at split point")
+	for _, v := range []float64{0.25, 0.5, 0.75} {
+		assert.InDeltaSlice(t, DeCasteljau(controls, u*v), DeCasteljau(left, v), delta, "left at %v", v)
+		assert.InDeltaSlice(t, DeCasteljau(controls, u+(1-u)*v), DeCasteljau(right, v), delta, "right at %v", v)
+	}
+}
+
+func TestHodographControls(t *testing.T) {
+	controls := []Vec{NewVec(0, 0), NewVec(1, 2), NewVec(3, 2), NewVec(4, 0)}
+	assert.Equal(t, []Vec{NewVec(3, 6), NewVec(6, 0), NewVec(3, -6)}, HodographControls(controls), "derivative controls")
+	assert.Nil(t, HodographControls(controls[:1]), "single control has no hodograph")
+}
+
+func TestIsFlatBezier(t *testing.T) {
+	assert.True(t, IsFlatBezier([]Vec{NewVec(0, 0), NewVec(1, 0.1), NewVec(2, 0)}, 0.1), "within distance")
+	assert.False(t, IsFlatBezier([]Vec{NewVec(0, 0), NewVec(1, 0.2), NewVec(2, 0)}, 0.1), "beyond distance")
+	assert.True(t, IsFlatBezier([]Vec{NewVec(0, 0), NewVec(2, 0)}, 0), "a line is flat")
+	assert.False(t, IsFlatBezier([]Vec{NewVec(0, 0), NewVec(0.2, 0), NewVec(0, 0)}, 0.1), "closed loop beyond distance to start")
+	assert.True(t, IsFlatBezier([]Vec{NewVec(0, 0), NewVec(0.05, 0), NewVec(0, 0)}, 0.1), "closed loop within distance to start")
+}
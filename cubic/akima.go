@@ -0,0 +1,156 @@
+package cubic
+
+import (
+	"github.com/walpod/bendigo"
+	"math"
+)
+
+// AkimaVertBuilder is an hermite vertex-based builder for Akima splines, whose tangents are weighted averages of the
+// neighboring secants. A tangent only depends on the two vertices on each side, so an outlier only affects its
+// neighborhood. The modified variant (makima) additionally avoids overshoot at flat regions
+type AkimaVertBuilder struct {
+	HermiteVertBuilder
+	modified bool
+}
+
+func NewAkimaVertBuilder(tknots []float64, vertices ...*EnexVertex) *AkimaVertBuilder {
+	sb := &AkimaVertBuilder{
+		HermiteVertBuilder: *NewHermiteVertBuilder(tknots, vertices...)}
+	sb.CalcTangents()
+	return sb
+}
+
+// NewMakimaVertBuilder creates a modified Akima builder
+func NewMakimaVertBuilder(tknots []float64, vertices ...*EnexVertex) *AkimaVertBuilder {
+	sb := &AkimaVertBuilder{
+		HermiteVertBuilder: *NewHermiteVertBuilder(tknots, vertices...),
+		modified:           true}
+	sb.CalcTangents()
+	return sb
+}
+
+// Modified returns true for the modified Akima variant (makima)
+func (sb *AkimaVertBuilder) Modified() bool {
+	return sb.modified
+}
+
+func (sb *AkimaVertBuilder) SetModified(modified bool) {
+	sb.modified = modified
+	sb.CalcTangents()
+}
+
+// SetParameterization derives the knots from the vertex locations, see HermiteVertBuilder.SetParameterization
+func (sb *AkimaVertBuilder) SetParameterization(parameterization bendigo.Parameterization) {
+	sb.HermiteVertBuilder.SetParameterization(parameterization)
+	sb.CalcTangents()
+}
+
+// SetClosed adds or removes the closing segment and recalculates the tangents, see HermiteVertBuilder.SetClosed
+func (sb *AkimaVertBuilder) SetClosed(closed bool) {
+	sb.HermiteVertBuilder.SetClosed(closed)
+	sb.CalcTangents()
+}
+
+// AddVertex adds the vertex and recalculates the tangents of the two vertices on each side
+func (sb *AkimaVertBuilder) AddVertex(knotNo int, vertex bendigo.Vertex) (err error) {
+	err = sb.HermiteVertBuilder.AddVertex(knotNo, vertex)
+	if err == nil {
+		sb.calcTangentsBetween(knotNo-2, knotNo+2)
+	}
+	return err
+}
+
+// UpdateVertex updates the vertex and recalculates the tangents of the two vertices on each side
+func (sb *AkimaVertBuilder) UpdateVertex(knotNo int, vertex bendigo.Vertex) (err error) {
+	err = sb.HermiteVertBuilder.UpdateVertex(knotNo, vertex)
+	if err == nil {
+		sb.calcTangentsBetween(knotNo-2, knotNo+2)
+	}
+	return err
+}
+
+// DeleteVertex deletes the vertex and recalculates the tangents of the former neighborhood
+func (sb *AkimaVertBuilder) DeleteVertex(knotNo int) (err error) {
+	err = sb.HermiteVertBuilder.DeleteVertex(knotNo)
+	if err == nil {
+		sb.calcTangentsBetween(knotNo-2, knotNo+1)
+	}
+	return err
+}
+
+// CalcTangents calculates and sets the tangents of all vertices, see "A New Method of Interpolation and Smooth
+// Curve Fitting Based on Local Procedures" (H. Akima). With secants s the tangent at vertex i is
+//
+//	m[i] = (w1*s[i-1] + w2*s[i]) / (w1 + w2),  w1 = |s[i+1]-s[i]|, w2 = |s[i-1]-s[i-2]|
+//
+// per dimension, the modified variant adds |s[i+1]+s[i]|/2 to w1 and |s[i-1]+s[i-2]|/2 to w2.
+// Open splines extrapolate two secants linearly at each end, closed splines wrap around
+func (sb *AkimaVertBuilder) CalcTangents() {
+	sb.calcTangentsBetween(0, len(sb.vertices)-1)
+}
+
+// calcTangentsBetween calculates and sets the tangents of the vertices fromKnotNo ... toKnotNo, limited to the
+// existing ones. Closed splines and parameterizations with non-local knot changes recalculate all tangents
+func (sb *AkimaVertBuilder) calcTangentsBetween(fromKnotNo, toKnotNo int) {
+	n := len(sb.vertices)
+	if n < 2 {
+		return
+	}
+	switch sb.parameterization.(type) {
+	case nil, bendigo.UniformParameterization, bendigo.AlphaParameterization:
+		// a vertex only affects the lengths of its adjacent segments
+	default:
+		fromKnotNo, toKnotNo = 0, n-1
+	}
+	if sb.closed || fromKnotNo < 0 {
+		fromKnotNo = 0
+	}
+	if sb.closed || toKnotNo > n-1 {
+		toKnotNo = n - 1
+	}
+	dim := sb.vertices[0].loc.Dim()
+	segmCnt := sb.knots.SegmentCnt()
+
+	// secant of segment no. j, zero for vanishing segments
+	secant := func(j, d int) float64 {
+		h, _ := sb.knots.SegmentLen(j)
+		if h == 0 {
+			return 0
+		}
+		return (sb.vertices[(j+1)%n].loc[d] - sb.vertices[j].loc[d]) / h
+	}
+	// slope of segment no. j, including the extrapolated ones beyond the ends of open splines
+	var slope func(j, d int) float64
+	slope = func(j, d int) float64 {
+		switch {
+		case sb.closed:
+			return secant((j%segmCnt+segmCnt)%segmCnt, d)
+		case segmCnt == 1:
+			return secant(0, d)
+		case j < 0:
+			return 2*slope(j+1, d) - slope(j+2, d)
+		case j >= segmCnt:
+			return 2*slope(j-1, d) - slope(j-2, d)
+		default:
+			return secant(j, d)
+		}
+	}
+
+	for i := fromKnotNo; i <= toKnotNo; i++ {
+		tan := bendigo.NewZeroVec(dim)
+		for d := 0; d < dim; d++ {
+			s0, s1, s2, s3 := slope(i-2, d), slope(i-1, d), slope(i, d), slope(i+1, d)
+			w1, w2 := math.Abs(s3-s2), math.Abs(s1-s0)
+			if sb.modified {
+				w1 += math.Abs(s3+s2) / 2
+				w2 += math.Abs(s1+s0) / 2
+			}
+			if w1+w2 == 0 {
+				tan[d] = (s1 + s2) / 2
+			} else {
+				tan[d] = (w1*s1 + w2*s2) / (w1 + w2)
+			}
+		}
+		sb.vertices[i].entry, sb.vertices[i].exit = tan, tan
+	}
+}
@@ -0,0 +1,94 @@
+package cubic
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"math"
+	"testing"
+)
+
+// createOutlierVertices creates measurements on the x-axis with a single outlier at x = 5
+func createOutlierVertices() []*EnexVertex {
+	vertices := make([]*EnexVertex, 11)
+	for i := range vertices {
+		y := 0.
+		if i == 5 {
+			y = 3
+		}
+		vertices[i] = NewRawHermiteVertex(bendigo.NewVec(float64(i), y))
+	}
+	return vertices
+}
+
+func TestAkimaVertBuilder_Outlier(t *testing.T) {
+	for _, modified := range []bool{false, true} {
+		sb := NewAkimaVertBuilder(nil, createOutlierVertices()...)
+		sb.SetModified(modified)
+		spline := sb.Spline()
+		for i := 0; i <= 100; i++ {
+			at := float64(i) / 10
+			if at <= 3 || at >= 7 {
+				assert.InDelta(t, 0, spline.At(at)[1], delta, "unaffected by outlier at %v", at)
+			}
+		}
+		AssertSplineAt(t, spline, 5, bendigo.NewVec(5, 3))
+	}
+
+	// natural spline ripples over the whole range
+	natural := NewNaturalVertBuilder(nil, createOutlierVertices()...).Spline()
+	assert.Greater(t, math.Abs(natural.At(1.5)[1]), 0.01)
+}
+
+func TestAkimaVertBuilder_NonUniform(t *testing.T) {
+	// straight line with uneven knots is reproduced exactly
+	tknots := []float64{0, 0.5, 2, 2.5, 4}
+	line := func(t float64) float64 { return 3*t - 1 }
+	vertices := make([]*EnexVertex, len(tknots))
+	for i, tk := range tknots {
+		vertices[i] = NewRawHermiteVertex(bendigo.NewVec(line(tk)))
+	}
+	for _, sb := range []*AkimaVertBuilder{NewAkimaVertBuilder(tknots, vertices...), NewMakimaVertBuilder(tknots, vertices...)} {
+		for _, vt := range sb.vertices {
+			assert.InDelta(t, 3, vt.Exit()[0], delta)
+		}
+	}
+
+	// flat region: makima keeps zero tangents, akima takes the mean of equal weights
+	sb := NewAkimaVertBuilder(nil,
+		NewRawHermiteVertex(bendigo.NewVec(0)),
+		NewRawHermiteVertex(bendigo.NewVec(0)),
+		NewRawHermiteVertex(bendigo.NewVec(1)),
+		NewRawHermiteVertex(bendigo.NewVec(1)),
+		NewRawHermiteVertex(bendigo.NewVec(1)),
+	)
+	assert.InDelta(t, 0.5, sb.vertices[1].Exit()[0], delta)
+	assert.InDelta(t, 0, sb.vertices[3].Exit()[0], delta)
+	sb.SetModified(true)
+	assert.True(t, sb.Modified())
+	assert.InDelta(t, 0, sb.vertices[3].Exit()[0], delta)
+}
+
+func TestAkimaVertBuilder_LocalUpdate(t *testing.T) {
+	assertTangentsEqual := func(expected, actual *AkimaVertBuilder, msg string) {
+		assert.Equal(t, len(expected.vertices), len(actual.vertices))
+		for i := range expected.vertices {
+			bendigotest.AssertVecInDelta(t, expected.vertices[i].Exit(), actual.vertices[i].Exit(), msg)
+		}
+	}
+	for _, parameterization := range []bendigo.Parameterization{nil, bendigo.CentripetalParameterization()} {
+		sb := NewMakimaVertBuilder(nil, createOutlierVertices()...)
+		sb.SetParameterization(parameterization)
+
+		assert.Nil(t, sb.UpdateVertex(1, NewRawHermiteVertex(bendigo.NewVec(1, -2))))
+		assert.Nil(t, sb.AddVertex(8, NewRawHermiteVertex(bendigo.NewVec(7.5, 1))))
+		assert.Nil(t, sb.DeleteVertex(3))
+
+		locs := make([]*EnexVertex, len(sb.vertices))
+		for i, vt := range sb.vertices {
+			locs[i] = NewRawHermiteVertex(vt.Loc())
+		}
+		full := NewMakimaVertBuilder(sb.Knots().External(), locs...)
+		assertTangentsEqual(full, sb, "local recalculation matches full one")
+	}
+}
@@ -11,8 +11,10 @@ func NewBezierVertex(loc, entry, exit bendigo.Vec) *EnexVertex {
 }
 
 type BezierVertBuilder struct {
-	knots    bendigo.Knots
-	vertices []*EnexVertex
+	knots            bendigo.Knots
+	vertices         []*EnexVertex
+	parameterization bendigo.Parameterization // nil: knots are maintained explicitly
+	closed           bool                     // additional segment from the last vertex back to the first one
 }
 
 func NewBezierVertBuilder(tknots []float64, vertices ...*EnexVertex) *BezierVertBuilder {
@@ -47,10 +49,10 @@ func NewBezierVertBuilderByMatrix(tknots []float64, dim int, mat mat.Dense) *Bez
 	vertices = append(vertices, NewBezierVertex(v, bendigo.NewZeroVec(dim), exit))
 
 	// intermediate vertices
-	v = bendigo.NewZeroVec(dim)
-	entry = bendigo.NewZeroVec(dim)
-	exit = bendigo.NewZeroVec(dim)
 	for i := 1; i < segmCnt; i++ {
+		v = bendigo.NewZeroVec(dim)
+		entry = bendigo.NewZeroVec(dim)
+		exit = bendigo.NewZeroVec(dim)
 		for d := 0; d < dim; d, row = d+1, row+1 {
 			v[d] = mat.At(row, 0)
 			entry[d] = mat.At(row-dim, 2)
@@ -93,10 +95,67 @@ func (sb *BezierVertBuilder) BezierVertex(knotNo int) *EnexVertex {
 }
 
 func (sb *BezierVertBuilder) Vertex(knotNo int) bendigo.Vertex {
-	return sb.BezierVertex(knotNo)
+	if vertex := sb.BezierVertex(knotNo); vertex != nil {
+		return vertex
+	}
+	return nil // avoid a non-nil interface holding a nil pointer
+}
+
+// Parameterization returns the parameterization the knots are derived from, nil if they are maintained explicitly
+func (sb *BezierVertBuilder) Parameterization() bendigo.Parameterization {
+	return sb.parameterization
+}
+
+// SetParameterization derives the knots from the vertex locations by given parameterization, now and after each
+// AddVertex, UpdateVertex and DeleteVertex. nil keeps the current knots and maintains them explicitly afterwards.
+// SplitAt keeps the exact split knots until the next modification
+func (sb *BezierVertBuilder) SetParameterization(parameterization bendigo.Parameterization) {
+	sb.parameterization = parameterization
+	sb.applyParameterization()
+}
+
+// applyParameterization recalculates the knots if a parameterization is set
+func (sb *BezierVertBuilder) applyParameterization() {
+	if sb.parameterization != nil {
+		sb.knots = sb.parameterization.Knots(closedLocs(sb.vertices, sb.closed))
+	}
+}
+
+// Closed returns true if the spline continues from the last vertex back to the first one
+func (sb *BezierVertBuilder) Closed() bool {
+	return sb.closed
+}
+
+// SetClosed adds (closed) or removes (!closed) the segment from the last vertex back to the first one together with
+// its closing knot, which is derived from the parameterization if set, else the closing segment gets the mean length
+// of the other segments (1 if uniform)
+func (sb *BezierVertBuilder) SetClosed(closed bool) {
+	if closed == sb.closed {
+		return
+	}
+	sb.closed = closed
+	if sb.parameterization != nil && len(sb.vertices) > 0 {
+		sb.applyParameterization()
+	} else {
+		sb.knots = closingKnots(sb.knots, closed)
+	}
+}
+
+// segmentVertices returns the start and end vertex of a segment, the closing segment ends at the first vertex
+func (sb *BezierVertBuilder) segmentVertices(segmentNo int) (vstart, vend *EnexVertex) {
+	return sb.vertices[segmentNo], sb.vertices[(segmentNo+1)%len(sb.vertices)]
 }
 
 func (sb *BezierVertBuilder) AddVertex(knotNo int, vertex bendigo.Vertex) (err error) {
+	err = sb.insertVertex(knotNo, vertex)
+	if err == nil {
+		sb.applyParameterization()
+	}
+	return err
+}
+
+// insertVertex adds the vertex and a knot without applying the parameterization
+func (sb *BezierVertBuilder) insertVertex(knotNo int, vertex bendigo.Vertex) (err error) {
 	err = sb.knots.AddKnot(knotNo)
 	if err != nil {
 		return err
@@ -113,14 +172,18 @@ func (sb *BezierVertBuilder) AddVertex(knotNo int, vertex bendigo.Vertex) (err e
 }
 
 func (sb *BezierVertBuilder) UpdateVertex(knotNo int, vertex bendigo.Vertex) (err error) {
-	if !sb.knots.KnotExists(knotNo) {
+	if knotNo < 0 || knotNo >= len(sb.vertices) { // the closing knot has no vertex of its own
 		return fmt.Errorf("knotNo %v does not exist", knotNo)
 	}
 	sb.vertices[knotNo] = vertex.(*EnexVertex)
+	sb.applyParameterization()
 	return nil
 }
 
 func (sb *BezierVertBuilder) DeleteVertex(knotNo int) (err error) {
+	if knotNo < 0 || knotNo >= len(sb.vertices) {
+		return fmt.Errorf("knotNo %v does not exist", knotNo)
+	}
 	err = sb.knots.DeleteKnot(knotNo)
 	if err != nil {
 		return err
@@ -130,9 +193,42 @@ func (sb *BezierVertBuilder) DeleteVertex(knotNo int) (err error) {
 	} else {
 		sb.vertices = append(sb.vertices[:knotNo], sb.vertices[knotNo+1:]...)
 	}
+	sb.applyParameterization()
 	return nil
 }
 
+// SplitAt inserts a new vertex at parameter t without changing the shape of the spline, using De Casteljau subdivision.
+// The controls of the neighbouring vertices are adjusted and they stop leading, knotNo of the new vertex is returned.
+// For non-uniform knots the split segments get lengths according to t, so that the parameterization is retained
+func (sb *BezierVertBuilder) SplitAt(t float64) (knotNo int, err error) {
+	segmentNo, u, err := sb.knots.MapToSegment(t)
+	if err != nil {
+		return -1, err
+	}
+	if u == 0 {
+		return segmentNo, nil // vertex already exists
+	} else if u == 1 {
+		return (segmentNo + 1) % len(sb.vertices), nil // end of closing segment is the first vertex
+	}
+
+	segmentLen, _ := sb.knots.SegmentLen(segmentNo)
+	left, right := splitBezier(sb.segmentControls(segmentNo), u)
+	vstart, vend := sb.segmentVertices(segmentNo)
+	vstart.setControlIndependently(left[1], false)
+	vend.setControlIndependently(right[2], true)
+
+	knotNo = segmentNo + 1
+	err = sb.insertVertex(knotNo, NewEnexVertexDep(left[3], left[2], right[1], false, false, false))
+	if err != nil {
+		return -1, err
+	}
+	if !sb.knots.IsUniform() {
+		_ = sb.knots.SetSegmentLen(segmentNo, u*segmentLen)
+		_ = sb.knots.SetSegmentLen(segmentNo+1, (1-u)*segmentLen)
+	}
+	return knotNo, nil
+}
+
 func (sb *BezierVertBuilder) Canonical() *CanonicalSpline {
 	n := len(sb.vertices)
 	if n >= 2 {
@@ -155,7 +251,7 @@ func (sb *BezierVertBuilder) uniCanonical() *CanonicalSpline {
 
 	avs := make([]float64, 0, segmCnt*dim*4)
 	for i := 0; i < segmCnt; i++ {
-		vstart, vend := sb.vertices[i], sb.vertices[i+1]
+		vstart, vend := sb.segmentVertices(i)
 		for d := 0; d < dim; d++ {
 			avs = append(avs, vstart.loc[d], vstart.exit[d], vend.entry[d], vend.loc[d])
 		}
@@ -176,8 +272,88 @@ func (sb *BezierVertBuilder) uniCanonical() *CanonicalSpline {
 }
 
 func (sb *BezierVertBuilder) nonUniCanonical() *CanonicalSpline {
-	// TODO implement non-uniform
-	panic("not yet implemented")
+	// precondition: segmCnt >= 1, !sb.knots.IsUniform()
+	// bezier controls define the shape of a segment depending on segment-local u, knots only affect the parameterization
+	segmCnt := sb.knots.SegmentCnt()
+	cubics := make([]CubicPolies, segmCnt)
+	for i := 0; i < segmCnt; i++ {
+		cubics[i] = sb.segmentCubics(i)
+	}
+	return NewCanonicalSpline(sb.knots.External(), cubics...)
+}
+
+// Hermite converts the bezier builder into an hermite builder with tangents as derivatives by t
+func (sb *BezierVertBuilder) Hermite() *HermiteVertBuilder {
+	n := len(sb.vertices)
+	dim := sb.Dim()
+	vertices := make([]*EnexVertex, n)
+	for i := 0; i < n; i++ {
+		vt := sb.vertices[i]
+		var entry, exit bendigo.Vec
+		if i > 0 || sb.closed {
+			if sgl, _ := sb.knots.SegmentLen((i + n - 1) % n); sgl != 0 {
+				entry = vt.loc.Sub(vt.entry).Scale(3 / sgl)
+			} else {
+				entry = bendigo.NewZeroVec(dim)
+			}
+		}
+		if i < sb.knots.SegmentCnt() {
+			if sgl, _ := sb.knots.SegmentLen(i); sgl != 0 {
+				exit = vt.exit.Sub(vt.loc).Scale(3 / sgl)
+			} else {
+				exit = bendigo.NewZeroVec(dim)
+			}
+		}
+		// unused controls at start and end are set to the tangent on the other side
+		if entry == nil && exit == nil {
+			entry, exit = bendigo.NewZeroVec(dim), bendigo.NewZeroVec(dim)
+		} else if entry == nil {
+			entry = exit
+		} else if exit == nil {
+			exit = entry
+		}
+		vertices[i] = NewEnexVertexDep(vt.loc, entry, exit, true, false, false)
+	}
+	herm := NewHermiteVertBuilder(nil, vertices...)
+	herm.knots, herm.closed = cloneKnots(sb.knots), sb.closed
+	return herm
+}
+
+// segmentCubics converts the bezier controls of a segment into cubic polynomials in power basis
+func (sb *BezierVertBuilder) segmentCubics(segmentNo int) CubicPolies {
+	c := sb.segmentControls(segmentNo)
+	return bezierCubics(c[0], c[1], c[2], c[3])
+}
+
+// bezierCubics converts the bezier controls p0 ... p3 into cubic polynomials in power basis
+func bezierCubics(p0, p1, p2, p3 bendigo.Vec) CubicPolies {
+	cubs := make([]CubicPoly, p0.Dim())
+	for d := range cubs {
+		cubs[d] = NewCubicPoly(p0[d], 3*(p1[d]-p0[d]), 3*(p0[d]-2*p1[d]+p2[d]), -p0[d]+3*p1[d]-3*p2[d]+p3[d])
+	}
+	return NewCubicPolies(cubs...)
+}
+
+// SegmentBBox calculates the exact bounding box of a segment
+func (sb *BezierVertBuilder) SegmentBBox(segmentNo int) (*bendigo.BBox, error) {
+	if !sb.knots.SegmentExists(segmentNo) {
+		return nil, fmt.Errorf("segment with no. %v doesn't exist", segmentNo)
+	}
+	cubs := sb.segmentCubics(segmentNo)
+	return cubs.BBox(0, 1), nil
+}
+
+// BBox calculates the exact bounding box of the spline, nil if there are no vertices
+func (sb *BezierVertBuilder) BBox() *bendigo.BBox {
+	if len(sb.vertices) == 1 {
+		return bendigo.NewBBox(sb.vertices[0].loc)
+	}
+	var bb *bendigo.BBox
+	for segmentNo := 0; segmentNo < sb.knots.SegmentCnt(); segmentNo++ {
+		sbb, _ := sb.SegmentBBox(segmentNo)
+		bb = bb.Union(sbb)
+	}
+	return bb
 }
 
 func (sb *BezierVertBuilder) Spline() bendigo.Spline {
@@ -192,7 +368,7 @@ func (sb *BezierVertBuilder) DeCasteljauSpline() *DeCasteljauSpline {
 
 	controls := make([]bendigo.Vec, 0, segmentCnt*4)
 	for s := 0; s < segmentCnt; s++ {
-		vtstart, vtend := sb.vertices[s], sb.vertices[s+1]
+		vtstart, vtend := sb.segmentVertices(s)
 		controls = append(controls, vtstart.loc, vtstart.exit, vtend.entry, vtend.loc)
 	}
 
@@ -200,49 +376,46 @@ func (sb *BezierVertBuilder) DeCasteljauSpline() *DeCasteljauSpline {
 }
 
 func (sb *BezierVertBuilder) LinApproximate(fromSegmentNo, toSegmentNo int, consumer bendigo.LineConsumer, linaxParams *bendigo.LinaxParams) {
-	dim := sb.Dim()
-
-	isFlat := func(v0, v1, v2, v3 bendigo.Vec) bool {
-		v03 := v3.Sub(v0)
-		return v1.Sub(v0).ProjectedVecDist(v03) <= linaxParams.MaxDist && v2.Sub(v0).ProjectedVecDist(v03) <= linaxParams.MaxDist
-	}
-
-	var subdivide func(segmentNo int, ts, te float64, v0, v1, v2, v3 bendigo.Vec)
-	subdivide = func(segmentNo int, ts, te float64, v0, v1, v2, v3 bendigo.Vec) {
-		if isFlat(v0, v1, v2, v3) {
-			consumer.ConsumeLine(segmentNo, ts, te, v0, v3)
-		} else {
-			m := 0.5
-			tm := ts*m + te*m
-			v01 := bendigo.NewZeroVec(dim)
-			v11 := bendigo.NewZeroVec(dim)
-			v21 := bendigo.NewZeroVec(dim)
-			v02 := bendigo.NewZeroVec(dim)
-			v12 := bendigo.NewZeroVec(dim)
-			v03 := bendigo.NewZeroVec(dim)
-			for d := 0; d < dim; d++ {
-				v01[d] = m*v0[d] + m*v1[d]
-				v11[d] = m*v1[d] + m*v2[d]
-				v21[d] = m*v2[d] + m*v3[d]
-				v02[d] = m*v01[d] + m*v11[d]
-				v12[d] = m*v11[d] + m*v21[d]
-				v03[d] = m*v02[d] + m*v12[d]
-			}
-			subdivide(segmentNo, ts, tm, v0, v01, v02, v03)
-			subdivide(segmentNo, tm, te, v03, v12, v21, v3)
-		}
-	}
-
 	// subdivide each segment
 	for segmentNo := fromSegmentNo; segmentNo <= toSegmentNo; segmentNo++ {
 		tstart, tend, err := bendigo.SegmentTrange(sb.knots, segmentNo)
 		if err == nil { // ignore nonexistent segments
-			vtstart, vtend := sb.vertices[segmentNo], sb.vertices[segmentNo+1]
-			subdivide(segmentNo, tstart, tend, vtstart.loc, vtstart.exit, vtend.entry, vtend.loc)
+			subdivideBezier(sb.segmentControls(segmentNo), tstart, tend, linaxParams.MaxDist,
+				func(ts, te float64, pstart, pend bendigo.Vec) {
+					consumer.ConsumeLine(segmentNo, ts, te, pstart, pend)
+				})
 		}
 	}
 }
 
+// subdivideBezier approximates the bezier controls with parameter range ts ... te by lines within maxDist,
+// recursively splitting them in the middle until they are flat
+func subdivideBezier(controls [4]bendigo.Vec, ts, te, maxDist float64, line func(ts, te float64, pstart, pend bendigo.Vec)) {
+	if bendigo.IsFlatBezier(controls[:], maxDist) {
+		line(ts, te, controls[0], controls[3])
+	} else {
+		m := 0.5
+		tm := ts*m + te*m
+		left, right := splitBezier(controls, m)
+		subdivideBezier(left, ts, tm, maxDist, line)
+		subdivideBezier(right, tm, te, maxDist, line)
+	}
+}
+
+// segmentControls returns the 4 bezier controls of a segment
+func (sb *BezierVertBuilder) segmentControls(segmentNo int) [4]bendigo.Vec {
+	vtstart, vtend := sb.segmentVertices(segmentNo)
+	return [4]bendigo.Vec{vtstart.loc, vtstart.exit, vtend.entry, vtend.loc}
+}
+
+// splitBezier subdivides the 4 cubic bezier controls at u using De Casteljau algorithm
+func splitBezier(controls [4]bendigo.Vec, u float64) (left, right [4]bendigo.Vec) {
+	lc, rc := bendigo.SplitBezier(controls[:], u)
+	copy(left[:], lc)
+	copy(right[:], rc)
+	return left, right
+}
+
 func (sb *BezierVertBuilder) LinaxSpline(linaxParams *bendigo.LinaxParams) *bendigo.LinaxSpline {
 	return bendigo.BuildLinaxSpline(sb, linaxParams)
 }
@@ -261,6 +434,13 @@ func (sp DeCasteljauSpline) Knots() bendigo.Knots {
 	return sp.knots
 }
 
+func (sp DeCasteljauSpline) Dim() int {
+	if len(sp.controls) == 0 {
+		return 0
+	}
+	return sp.controls[0].Dim()
+}
+
 func (sp DeCasteljauSpline) At(t float64) bendigo.Vec {
 	segmentNo, u, err := sp.knots.MapToSegment(t)
 	if err != nil {
@@ -272,7 +452,6 @@ func (sp DeCasteljauSpline) At(t float64) bendigo.Vec {
 		dim = sp.controls[0].Dim()
 	}
 
-	// TODO prepare u for non-uniform
 	linip := func(a, b float64) float64 { // linear interpolation
 		return a + u*(b-a)
 	}
@@ -289,3 +468,68 @@ func (sp DeCasteljauSpline) At(t float64) bendigo.Vec {
 	}
 	return p
 }
+
+func (sp DeCasteljauSpline) AtInto(ts []float64, dst []float64) (err error) {
+	dim := sp.Dim()
+	if len(dst) < len(ts)*dim {
+		return fmt.Errorf("destination has length %v, but %v values are required", len(dst), len(ts)*dim)
+	}
+
+	cursor := bendigo.NewSegmentCursor(sp.knots)
+	for i, t := range ts {
+		segmentNo, u, err := cursor.MapToSegment(t)
+		if err != nil {
+			return err
+		}
+		idx := segmentNo * 4
+		start, exit, entry, end := sp.controls[idx], sp.controls[idx+1], sp.controls[idx+2], sp.controls[idx+3]
+		for d := 0; d < dim; d++ {
+			b01 := start[d] + u*(exit[d]-start[d])
+			b11 := exit[d] + u*(entry[d]-exit[d])
+			b21 := entry[d] + u*(end[d]-entry[d])
+			b02 := b01 + u*(b11-b01)
+			b12 := b11 + u*(b21-b11)
+			dst[i*dim+d] = b02 + u*(b12-b02)
+		}
+	}
+	return nil
+}
+
+// Deriv calculates the derivative of given order at parameter t using the hodographs of the bezier segment
+func (sp DeCasteljauSpline) Deriv(t float64, order int) bendigo.Vec {
+	if order < 0 {
+		return nil
+	}
+	segmentNo, u, err := sp.knots.MapToSegment(t)
+	if err != nil {
+		return nil
+	}
+
+	// hodograph: derivative of a bezier curve of degree n is a bezier curve of degree n-1 with controls n*(b[i+1]-b[i])
+	idx := segmentNo * 4
+	controls := sp.controls[idx : idx+4]
+	for o := 0; o < order && len(controls) > 1; o++ {
+		controls = bendigo.HodographControls(controls)
+	}
+	if order > 3 {
+		return bendigo.NewZeroVec(controls[0].Dim())
+	}
+
+	return bendigo.DeCasteljau(controls, u).Scale(bendigo.DerivScale(sp.knots, segmentNo, order))
+}
+
+// Canonical converts the bezier controls into a canonical spline
+func (sp DeCasteljauSpline) Canonical() *CanonicalSpline {
+	segmentCnt := len(sp.controls) / 4
+	cubics := make([]CubicPolies, segmentCnt)
+	for s := 0; s < segmentCnt; s++ {
+		idx := s * 4
+		cubics[s] = bezierCubics(sp.controls[idx], sp.controls[idx+1], sp.controls[idx+2], sp.controls[idx+3])
+	}
+	return NewCanonicalSpline(sp.knots.External(), cubics...)
+}
+
+// ClosestPoint finds the point q on the spline with minimal distance to p and returns its parameter t
+func (sp DeCasteljauSpline) ClosestPoint(p bendigo.Vec) (t float64, q bendigo.Vec, dist float64, err error) {
+	return sp.Canonical().ClosestPoint(p)
+}
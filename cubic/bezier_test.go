@@ -4,43 +4,24 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"math"
 	"math/rand"
 	"testing"
 )
 
 // START some general bendigo spline Asserts ... TODO to be moved ...
-const delta = 0.0000000001 // TODO use as param in Assert...
-
-func AssertVecInDelta(t *testing.T, expected bendigo.Vec, actual bendigo.Vec, msg string) {
-	assert.Equal(t, expected.Dim(), actual.Dim(), "dimension of expected = %v != dimension of actual %v", expected.Dim(), actual.Dim())
-	for d := 0; d < expected.Dim(); d++ {
-		assert.InDeltaf(t, expected[d], actual[d], delta, msg+", at dim = %v", d)
-	}
-}
+const delta = bendigotest.Delta // TODO use as param in Assert...
 
 func AssertSplineAt(t *testing.T, spline bendigo.Spline, atT float64, expected bendigo.Vec) {
 	actual := spline.At(atT)
-	AssertVecInDelta(t, expected, actual, fmt.Sprintf("spline0.At(%v) = %v != spline1.At(%v) = %v", atT, expected, atT, actual))
-}
-
-func AssertSplinesEqualInRange(t *testing.T, spline0 bendigo.Spline, spline1 bendigo.Spline, tstart, tend float64, sampleCnt int) {
-	for i := 0; i < sampleCnt; i++ {
-		atT := rand.Float64()*(tend-tstart) + tstart
-		v0 := spline0.At(atT)
-		v1 := spline1.At(atT)
-		AssertVecInDelta(t, v0, v1, fmt.Sprintf("spline0.At(%v).x = %v != spline1.At(%v).x = %v", atT, v0, atT, v1))
-	}
-}
-
-func AssertSplinesEqual(t *testing.T, spline0 bendigo.Spline, spline1 bendigo.Spline, sampleCnt int) {
-	// assert over full domain
-	AssertSplinesEqualInRange(t, spline0, spline1, spline0.Knots().Tstart(), spline0.Knots().Tend(), sampleCnt)
+	bendigotest.AssertVecInDelta(t, expected, actual, fmt.Sprintf("spline0.At(%v) = %v != spline1.At(%v) = %v", atT, expected, atT, actual))
 }
 
 func AssertApproxStartPointsMatchSpline(t *testing.T, lines []bendigo.Line, spline bendigo.Spline) {
 	for _, lin := range lines {
 		v := spline.At(lin.Tstart)
-		AssertVecInDelta(t, v, lin.Pstart, fmt.Sprintf("spline.At(%v) = %v != start-point = %v of approximated line", lin.Tstart, v, lin.Pstart))
+		bendigotest.AssertVecInDelta(t, v, lin.Pstart, fmt.Sprintf("spline.At(%v) = %v != start-point = %v of approximated line", lin.Tstart, v, lin.Pstart))
 		//assert.InDeltaf(t, x, lin.Pstartx, delta, "spline.At(%v).x = %v != start-point.x = %v of approximated line", lin.Tstart, x, lin.Pstartx)
 	}
 }
@@ -52,6 +33,28 @@ func AssertRandSplinePointProperty(t *testing.T, spline bendigo.Spline, hasProp
 	assert.True(t, hasProp(v), msg)
 }
 
+// AssertUniformSplitKeepsShape asserts that after splitting segment segmentNo of a uniform spline at u the
+// shape is unchanged, i.e. the old segment maps to the two new segments
+func AssertUniformSplitKeepsShape(t *testing.T, before, after bendigo.Spline, segmentNo int, u float64) {
+	ts := float64(segmentNo)
+	for i := 0; i <= 10; i++ {
+		v := float64(i) / 10
+		AssertSplineAt(t, after, ts+v, before.At(ts+v*u))
+		AssertSplineAt(t, after, ts+1+v, before.At(ts+u+v*(1-u)))
+	}
+	bendigotest.AssertSplinesEqualInRange(t, before, shiftedSpline{after, 1}, ts+1, before.Knots().Tend(), 20)
+}
+
+// shiftedSpline evaluates a spline at t + shift
+type shiftedSpline struct {
+	bendigo.Spline
+	shift float64
+}
+
+func (sp shiftedSpline) At(t float64) bendigo.Vec {
+	return sp.Spline.At(t + sp.shift)
+}
+
 // END some general bendigo spline Asserts
 
 // createBezierDiag00to11 creates a bezier representing a straight line from (0,0) to (1,1)
@@ -79,6 +82,15 @@ func createDoubleBezierS00to11to22() *BezierVertBuilder {
 	)
 }
 
+// createNonUniDoubleBezierS00to11to22 creates two consecutive beziers like createDoubleBezierS00to11to22 with non-uniform knots
+func createNonUniDoubleBezierS00to11to22() *BezierVertBuilder {
+	return NewBezierVertBuilder([]float64{0, 0.5, 2},
+		NewBezierVertex(bendigo.NewVec(0, 0), nil, bendigo.NewVec(1, 0)),
+		NewBezierVertex(bendigo.NewVec(1, 1), nil, bendigo.NewVec(2, 1)),
+		NewBezierVertex(bendigo.NewVec(2, 2), bendigo.NewVec(1, 2), nil),
+	)
+}
+
 func TestBezierSpline(t *testing.T) {
 	bezier := createBezierDiag00to11().Spline()
 	AssertSplineAt(t, bezier, 0, bendigo.NewVec(0, 0))
@@ -111,17 +123,82 @@ func TestBezierSpline(t *testing.T) {
 	bezier = NewBezierVertBuilder([]float64{}).Spline()
 }
 
+func TestNonUniBezierSpline(t *testing.T) {
+	bezier := createNonUniDoubleBezierS00to11to22().Spline()
+	AssertSplineAt(t, bezier, 0, bendigo.NewVec(0, 0))
+	AssertSplineAt(t, bezier, 0.25, bendigo.NewVec(0.5, 0.5))
+	AssertSplineAt(t, bezier, 0.5, bendigo.NewVec(1, 1))
+	AssertSplineAt(t, bezier, 1.25, bendigo.NewVec(1.5, 1.5))
+	AssertSplineAt(t, bezier, 2, bendigo.NewVec(2, 2))
+
+	// same shape as uniform
+	uniBezier := createDoubleBezierS00to11to22().Spline()
+	for i := 0; i <= 10; i++ {
+		u := float64(i) / 10
+		AssertSplineAt(t, bezier, 0.5*u, uniBezier.At(u))
+		AssertSplineAt(t, bezier, 0.5+1.5*u, uniBezier.At(1+u))
+	}
+}
+
 func TestDeCasteljauSpline(t *testing.T) {
 	bezierBuilder := createBezierS00to11()
-	AssertSplinesEqual(t, bezierBuilder.Spline(), bezierBuilder.DeCasteljauSpline(), 100)
+	bendigotest.AssertSplinesEqual(t, bezierBuilder.Spline(), bezierBuilder.DeCasteljauSpline(), 100)
+
+	bezierBuilder = createNonUniDoubleBezierS00to11to22()
+	bendigotest.AssertSplinesEqual(t, bezierBuilder.Spline(), bezierBuilder.DeCasteljauSpline(), 100)
+}
+
+func TestBezierVertBuilder_Hermite(t *testing.T) {
+	for _, bezierBuilder := range []*BezierVertBuilder{createDoubleBezierS00to11to22(), createNonUniDoubleBezierS00to11to22()} {
+		hermBuilder := bezierBuilder.Hermite()
+		bendigotest.AssertSplinesEqual(t, bezierBuilder.Spline(), hermBuilder.Spline(), 100)
+
+		// round trip: bezier -> hermite -> bezier
+		roundTrip := hermBuilder.Bezier()
+		for i := 0; i < 3; i++ {
+			bendigotest.AssertVecInDelta(t, bezierBuilder.BezierVertex(i).Loc(), roundTrip.BezierVertex(i).Loc(), "round trip location")
+			if i > 0 {
+				bendigotest.AssertVecInDelta(t, bezierBuilder.BezierVertex(i).Entry(), roundTrip.BezierVertex(i).Entry(), "round trip entry")
+			}
+			if i < 2 {
+				bendigotest.AssertVecInDelta(t, bezierBuilder.BezierVertex(i).Exit(), roundTrip.BezierVertex(i).Exit(), "round trip exit")
+			}
+		}
+	}
+}
+
+func TestDeCasteljauSpline_AtInto(t *testing.T) {
+	AssertAtIntoMatchesAt(t, createDoubleBezierS00to11to22().DeCasteljauSpline(), 100)
+}
+
+func TestDeCasteljauSpline_Deriv(t *testing.T) {
+	bezierBuilder := createDoubleBezierS00to11to22()
+	canon, decas := bezierBuilder.Canonical(), bezierBuilder.DeCasteljauSpline()
+	for order := 0; order <= 4; order++ {
+		for i := 0; i <= 10; i++ {
+			atT := float64(i) / 10 * canon.Knots().Tend()
+			bendigotest.AssertVecInDelta(t, canon.Deriv(atT, order), decas.Deriv(atT, order),
+				fmt.Sprintf("derivative of order %v at %v must match canonical", order, atT))
+		}
+	}
+}
+
+func TestDeCasteljauSpline_ClosestPoint(t *testing.T) {
+	decas := createDoubleBezierS00to11to22().DeCasteljauSpline()
+	atT, q, dist, err := decas.ClosestPoint(bendigo.NewVec(2, 0))
+	assert.Nil(t, err)
+	bendigotest.AssertVecInDelta(t, decas.At(atT), q, "closest point must be on spline")
+	for j := 0; j <= 200; j++ {
+		assert.LessOrEqual(t, dist, decas.At(float64(j)/100).Sub(bendigo.NewVec(2, 0)).Len()+delta, "no sample may be closer")
+	}
 }
 
 func TestBezierLinaxSpline(t *testing.T) {
 	bezierBuilder := createBezierDiag00to11()
 	lines := bezierBuilder.LinaxSpline(bendigo.NewLinaxParams(0.1)).Lines()
 	assert.Len(t, lines, 1, "approximated with one line")
-	AssertVecInDelta(t, bendigo.NewVec(0, 0), lines[0].Pstart, "start point = [0,0]")
-	AssertVecInDelta(t, bendigo.NewVec(1, 1), lines[0].Pend, "end point = [1,1]")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), lines[0].Pstart, "start point = [0,0]")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 1), lines[0].Pend, "end point = [1,1]")
 
 	// start points of approximated lines must be on bezier curve and match bezier.At
 	bezierBuilder = createBezierS00to11()
@@ -130,6 +207,88 @@ func TestBezierLinaxSpline(t *testing.T) {
 	AssertApproxStartPointsMatchSpline(t, lines, bezierBuilder.Spline())
 }
 
+func TestBezierVertBuilder_BBox(t *testing.T) {
+	bb := createBezierS00to11().BBox()
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), bb.Min, "minimum of S-slope")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 1), bb.Max, "maximum of S-slope")
+
+	// hump: controls reach y = 1, curve only y = 0.75
+	bezierBuilder := NewBezierVertBuilder(nil,
+		NewBezierVertex(bendigo.NewVec(0, 0), nil, bendigo.NewVec(0, 1)),
+		NewBezierVertex(bendigo.NewVec(1, 0), bendigo.NewVec(1, 1), nil))
+	bb = bezierBuilder.BBox()
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), bb.Min, "minimum of hump")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 0.75), bb.Max, "maximum of hump")
+
+	bezierBuilder = createDoubleBezierS00to11to22()
+	bb = bezierBuilder.BBox()
+	spline := bezierBuilder.Spline()
+	for i := 0; i <= 100; i++ {
+		assert.True(t, bb.Contains(spline.At(float64(i)/50)), "point must be inside bounding box")
+	}
+	sbb, err := bezierBuilder.SegmentBBox(1)
+	assert.Nil(t, err)
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 1), sbb.Min, "minimum of second segment")
+	_, err = bezierBuilder.SegmentBBox(2)
+	assert.NotNil(t, err, "segment doesn't exist")
+
+	// 3 dimensions
+	bezierBuilder = NewBezierVertBuilder(nil,
+		NewBezierVertex(bendigo.NewVec(0, 0, 0), nil, bendigo.NewVec(0, 1, -1)),
+		NewBezierVertex(bendigo.NewVec(1, 0, 0), bendigo.NewVec(1, 1, -1), nil))
+	bb = bezierBuilder.BBox()
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0, -0.75), bb.Min, "minimum in 3D")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 0.75, 0), bb.Max, "maximum in 3D")
+
+	assert.Nil(t, NewBezierVertBuilder(nil).BBox(), "empty bezier has no bounding box")
+}
+
+func TestBezierVertBuilder_SplitAt(t *testing.T) {
+	bezierBuilder := createDoubleBezierS00to11to22()
+	before := bezierBuilder.Spline()
+	knotNo, err := bezierBuilder.SplitAt(0.3)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, knotNo, "new vertex is second vertex")
+	assert.Equal(t, 4, bezierBuilder.knots.KnotCnt(), "one knot added")
+	bendigotest.AssertVecInDelta(t, before.At(0.3), bezierBuilder.Vertex(1).Loc(), "new vertex is on spline")
+	AssertUniformSplitKeepsShape(t, before, bezierBuilder.Spline(), 0, 0.3)
+
+	knotNo, _ = bezierBuilder.SplitAt(2)
+	assert.Equal(t, 2, knotNo, "split at existing knot returns its no.")
+	assert.Equal(t, 4, bezierBuilder.knots.KnotCnt(), "no knot added")
+	_, err = bezierBuilder.SplitAt(5)
+	assert.NotNil(t, err, "out of domain")
+}
+
+func TestBezierVertBuilder_SplitAt_NonUniform(t *testing.T) {
+	bezierBuilder := createNonUniDoubleBezierS00to11to22()
+	before := bezierBuilder.Spline()
+	knotNo, err := bezierBuilder.SplitAt(0.2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, knotNo, "new vertex is second vertex")
+	assert.Equal(t, []float64{0, 0.2, 0.5, 2}, bezierBuilder.knots.External(), "knot inserted")
+	bendigotest.AssertSplinesEqual(t, before, bezierBuilder.Spline(), 100)
+	knotNo, err = bezierBuilder.SplitAt(1.5)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, knotNo, "new vertex is fourth vertex")
+	assert.Equal(t, []float64{0, 0.2, 0.5, 1.5, 2}, bezierBuilder.knots.External(), "knot inserted")
+	bendigotest.AssertSplinesEqual(t, before, bezierBuilder.Spline(), 100)
+}
+
+func TestBezierVertBuilder_SplitAt_LeadingNeighbour(t *testing.T) {
+	bezierBuilder := createDoubleBezierS00to11to22()
+	middle := bezierBuilder.BezierVertex(1)
+	assert.True(t, middle.Leading())
+	_, err := bezierBuilder.SplitAt(0.5)
+	assert.Nil(t, err)
+	assert.False(t, middle.Leading(), "shortened entry doesn't mirror the exit")
+
+	// moving the exit keeps the split segment before the vertex
+	before := bezierBuilder.Spline()
+	middle.SetExit(bendigo.NewVec(3, 1))
+	bendigotest.AssertSplinesEqualInRange(t, before, bezierBuilder.Spline(), 0, 2, 20)
+}
+
 func TestBezierVertBuilder_AddVertex(t *testing.T) {
 	bezierBuilder := createBezierDiag00to11()
 	err := bezierBuilder.AddVertex(3, nil)
@@ -152,3 +311,69 @@ func TestBezierVertBuilder_DeleteVertex(t *testing.T) {
 	err = bezierBuilder.DeleteVertex(0)
 	assert.Equal(t, bezierBuilder.knots.KnotCnt(), 0, "knot-cnt %v wrong", bezierBuilder.knots.KnotCnt())
 }
+
+func TestBezierVertBuilder_SetParameterization(t *testing.T) {
+	sb := createDoubleBezierS00to11to22()
+	sb.SetParameterization(bendigo.CentripetalParameterization())
+	assert.InDeltaSlice(t, []float64{0, math.Pow(2, 0.25), 2 * math.Pow(2, 0.25)}, sb.Knots().External(), delta)
+	AssertSplineAt(t, sb.Spline(), math.Pow(2, 0.25), bendigo.NewVec(1, 1))
+
+	assert.Nil(t, sb.UpdateVertex(2, NewBezierVertex(bendigo.NewVec(1, 5), bendigo.NewVec(1, 4), nil)))
+	assert.InDeltaSlice(t, []float64{0, math.Pow(2, 0.25), math.Pow(2, 0.25) + 2}, sb.Knots().External(), delta)
+	assert.Nil(t, sb.DeleteVertex(0))
+	assert.InDeltaSlice(t, []float64{0, 2}, sb.Knots().External(), delta)
+}
+
+// createBezierLoop creates a closed bezier curve around the unit square through its corners
+func createBezierLoop(tknots []float64) *BezierVertBuilder {
+	sb := NewBezierVertBuilder(tknots,
+		NewBezierVertex(bendigo.NewVec(0, 0), bendigo.NewVec(-0.3, 0.3), bendigo.NewVec(0.3, -0.3)),
+		NewBezierVertex(bendigo.NewVec(1, 0), bendigo.NewVec(0.7, -0.3), bendigo.NewVec(1.3, 0.3)),
+		NewBezierVertex(bendigo.NewVec(1, 1), bendigo.NewVec(1.3, 0.7), bendigo.NewVec(0.7, 1.3)),
+		NewBezierVertex(bendigo.NewVec(0, 1), bendigo.NewVec(0.3, 1.3), bendigo.NewVec(-0.3, 0.7)),
+	)
+	sb.SetClosed(true)
+	return sb
+}
+
+func TestBezierVertBuilder_SetClosed(t *testing.T) {
+	sb := createBezierLoop(nil)
+	assert.True(t, sb.Closed())
+	assert.Equal(t, 5, sb.Knots().KnotCnt(), "closing knot added")
+	assert.Len(t, bendigo.Vertices(sb), 4, "closing knot has no vertex")
+	spline := sb.Canonical()
+	AssertSplineAt(t, spline, 4, bendigo.NewVec(0, 0))
+	AssertSplineAt(t, spline, 3.5, bendigo.NewVec(-0.225, 0.5))
+	bendigotest.AssertVecInDelta(t, spline.Deriv(0, 1), spline.Deriv(4, 1), "smooth seam")
+	bendigotest.AssertSplinesEqual(t, spline, sb.DeCasteljauSpline(), 100)
+
+	lines := sb.LinaxSpline(bendigo.NewLinaxParams(0.01)).Lines()
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), lines[len(lines)-1].Pend, "approximation ends at start point")
+	AssertApproxStartPointsMatchSpline(t, lines, spline)
+
+	// split the closing segment
+	before := sb.Spline()
+	knotNo, err := sb.SplitAt(3.5)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, knotNo, "new vertex is last vertex")
+	AssertUniformSplitKeepsShape(t, before, sb.Spline(), 3, 0.5)
+	knotNo, _ = sb.SplitAt(5)
+	assert.Equal(t, 0, knotNo, "end of closing segment is first vertex")
+
+	// non-uniform: closing segment gets mean length
+	sb = createBezierLoop([]float64{0, 1, 3, 4})
+	assert.Equal(t, []float64{0, 1, 3, 4, 5 + 1./3}, sb.Knots().External())
+	bendigotest.AssertSplinesEqual(t, sb.Spline(), sb.Hermite().Spline(), 100)
+	assert.Nil(t, sb.UpdateVertex(3, NewBezierVertex(bendigo.NewVec(0, 2), bendigo.NewVec(0.3, 2.3), bendigo.NewVec(-0.3, 1.7))))
+	assert.NotNil(t, sb.UpdateVertex(4, NewBezierVertex(bendigo.NewVec(0, 0), nil, nil)), "closing knot has no vertex")
+	assert.NotNil(t, sb.DeleteVertex(4), "closing knot has no vertex")
+
+	sb.SetParameterization(bendigo.ChordLengthParameterization())
+	assert.InDeltaSlice(t, []float64{0, 1, 2, 2 + math.Sqrt2, 4 + math.Sqrt2}, sb.Knots().External(), delta)
+	sb.SetClosed(false)
+	assert.InDeltaSlice(t, []float64{0, 1, 2, 2 + math.Sqrt2}, sb.Knots().External(), delta)
+	sb.SetParameterization(nil)
+	sb.SetClosed(true)
+	sb.SetClosed(false)
+	assert.InDeltaSlice(t, []float64{0, 1, 2, 2 + math.Sqrt2}, sb.Knots().External(), delta)
+}
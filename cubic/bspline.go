@@ -0,0 +1,299 @@
+package cubic
+
+import (
+	"fmt"
+	"github.com/walpod/bendigo"
+	"math"
+)
+
+// bsplineDegree is the degree of cubic b-splines, a knot vector has len(vertices) + bsplineDegree + 1 knots
+const bsplineDegree = 3
+
+// BSplineVertBuilder builds approximating cubic b-splines from control vertices.
+// The spline is defined on the domain knots knotVector[3] ... knotVector[len(vertices)], vertex no. i influences
+// the domain between knotVector[i] and knotVector[i+4]. The vertices are indexed by control no. and not by knot no.
+// as there are 2 vertices more than domain knots, hence it is no SplineVertBuilder
+type BSplineVertBuilder struct {
+	knotVector []float64
+	uniform    bool // knot vector is recreated uniformly on modification
+	clamped    bool // end knots have multiplicity 4, the spline interpolates first and last vertex
+	vertices   []*ControlVertex
+}
+
+// NewBSplineVertBuilder creates a b-spline builder, tknots: nil = uniform, else the domain knots (len(vertices) - 2).
+// The knot vector is extended at both ends, by repeating the end knots if clamped else by continuing the end segments
+func NewBSplineVertBuilder(tknots []float64, clamped bool, vertices ...*ControlVertex) *BSplineVertBuilder {
+	sb := &BSplineVertBuilder{uniform: tknots == nil, clamped: clamped, vertices: vertices}
+	if tknots == nil {
+		sb.knotVector = uniformKnotVector(len(vertices), clamped)
+	} else {
+		if len(vertices) >= bsplineDegree && len(tknots) != len(vertices)-bsplineDegree+1 {
+			panic("tknots must have length of vertices - 2")
+		}
+		sb.knotVector = extendKnotVector(tknots, len(vertices), clamped)
+	}
+	return sb
+}
+
+// NewBSplineVertBuilderByKnotVector creates a b-spline builder with an arbitrary non-decreasing knot vector
+// of length len(vertices) + 4
+func NewBSplineVertBuilderByKnotVector(knotVector []float64, vertices ...*ControlVertex) *BSplineVertBuilder {
+	if len(knotVector) != len(vertices)+bsplineDegree+1 {
+		panic("knot vector must have length of vertices + 4")
+	}
+	return &BSplineVertBuilder{knotVector: knotVector, clamped: isClampedKnotVector(knotVector), vertices: vertices}
+}
+
+// uniformKnotVector creates a uniform knot vector with domain knots 0, 1, ...
+func uniformKnotVector(vertexCnt int, clamped bool) []float64 {
+	knotVector := make([]float64, vertexCnt+bsplineDegree+1)
+	for i := range knotVector {
+		knotVector[i] = float64(i - bsplineDegree)
+		if clamped {
+			knotVector[i] = math.Max(0, math.Min(knotVector[i], float64(vertexCnt-bsplineDegree)))
+		}
+	}
+	return knotVector
+}
+
+// extendKnotVector extends the domain knots by 3 knots on both ends
+func extendKnotVector(tknots []float64, vertexCnt int, clamped bool) []float64 {
+	if len(tknots) == 0 {
+		return uniformKnotVector(vertexCnt, clamped)
+	}
+	if clamped {
+		return bendigo.ClampedKnotVector(bsplineDegree, tknots)
+	}
+	first, last := tknots[0], tknots[len(tknots)-1]
+	startLen, endLen := 1., 1.
+	if len(tknots) >= 2 {
+		startLen, endLen = tknots[1]-tknots[0], tknots[len(tknots)-1]-tknots[len(tknots)-2]
+	}
+	knotVector := make([]float64, 0, vertexCnt+bsplineDegree+1)
+	for i := bsplineDegree; i >= 1; i-- {
+		knotVector = append(knotVector, first-float64(i)*startLen)
+	}
+	knotVector = append(knotVector, tknots...)
+	for i := 1; i <= bsplineDegree; i++ {
+		knotVector = append(knotVector, last+float64(i)*endLen)
+	}
+	return knotVector
+}
+
+// Knots returns the domain knots, derived from the knot vector. They are a copy, modifications have no effect on
+// the builder, use SetKnotVector instead
+func (sb *BSplineVertBuilder) Knots() bendigo.Knots {
+	domainCnt := len(sb.vertices) - bsplineDegree + 1
+	if domainCnt < 0 {
+		domainCnt = 0
+	}
+	if sb.uniform {
+		return bendigo.NewUniformKnots(domainCnt)
+	}
+	tknots := make([]float64, domainCnt)
+	copy(tknots, sb.knotVector[bsplineDegree:])
+	return bendigo.NewNonUniformKnots(tknots)
+}
+
+// KnotVector returns a copy of the full knot vector
+func (sb *BSplineVertBuilder) KnotVector() []float64 {
+	knotVector := make([]float64, len(sb.knotVector))
+	copy(knotVector, sb.knotVector)
+	return knotVector
+}
+
+// SetKnotVector replaces the full knot vector, it must be non-decreasing with length len(vertices) + 4.
+// The knot vector is no longer recreated uniformly on modification
+func (sb *BSplineVertBuilder) SetKnotVector(knotVector []float64) (err error) {
+	if len(knotVector) != len(sb.vertices)+bsplineDegree+1 {
+		return fmt.Errorf("knot vector must have length %v", len(sb.vertices)+bsplineDegree+1)
+	}
+	for i := 1; i < len(knotVector); i++ {
+		if knotVector[i] < knotVector[i-1] {
+			return fmt.Errorf("knot vector must be non-decreasing, knot no. %v is smaller than its predecessor", i)
+		}
+	}
+	sb.knotVector = knotVector
+	sb.uniform = false
+	sb.clamped = isClampedKnotVector(knotVector)
+	return nil
+}
+
+// isClampedKnotVector checks if the end knots of the knot vector have multiplicity 4
+func isClampedKnotVector(knotVector []float64) bool {
+	n := len(knotVector)
+	return n >= bsplineDegree+1 && knotVector[0] == knotVector[bsplineDegree] && knotVector[n-1] == knotVector[n-1-bsplineDegree]
+}
+
+func (sb *BSplineVertBuilder) Clamped() bool {
+	return sb.clamped
+}
+
+func (sb *BSplineVertBuilder) Dim() int {
+	if len(sb.vertices) == 0 {
+		return 0
+	} else {
+		return sb.vertices[0].loc.Dim()
+	}
+}
+
+// ControlCnt returns the number of control vertices, which is the number of domain knots + 2
+func (sb *BSplineVertBuilder) ControlCnt() int {
+	return len(sb.vertices)
+}
+
+// Control returns the control vertex with given no.
+func (sb *BSplineVertBuilder) Control(controlNo int) *ControlVertex {
+	if controlNo < 0 || controlNo >= len(sb.vertices) {
+		return nil
+	} else {
+		return sb.vertices[controlNo]
+	}
+}
+
+// AddControl inserts a control vertex before the one with given no. or at the end. Non-uniform knot vectors get a
+// new domain knot in the middle of the span around the vertex' influence, end knots remain untouched.
+// Use SetKnotVector to adjust the knots afterwards
+func (sb *BSplineVertBuilder) AddControl(controlNo int, cv *ControlVertex) (err error) {
+	if controlNo < 0 || controlNo > len(sb.vertices) {
+		return fmt.Errorf("control must be added at existing control or at end, incorrect control no. %v", controlNo)
+	}
+	sb.vertices = append(sb.vertices, nil)
+	copy(sb.vertices[controlNo+1:], sb.vertices[controlNo:])
+	sb.vertices[controlNo] = cv
+
+	if sb.uniform {
+		sb.knotVector = uniformKnotVector(len(sb.vertices), sb.clamped)
+	} else if prevCnt := len(sb.vertices) - 1; prevCnt <= bsplineDegree {
+		// domain has less than 2 knots, extend it by a segment of length 1
+		var tknots []float64
+		if prevCnt == bsplineDegree {
+			tknots = []float64{sb.knotVector[bsplineDegree], sb.knotVector[bsplineDegree] + 1}
+		}
+		sb.knotVector = extendKnotVector(tknots, len(sb.vertices), sb.clamped)
+	} else {
+		// split a domain span: middle of the influence of the vertex, limited to the domain
+		k := clampInt(controlNo+2, bsplineDegree+1, prevCnt)
+		t := (sb.knotVector[k-1] + sb.knotVector[k]) / 2
+		sb.knotVector = append(sb.knotVector, 0)
+		copy(sb.knotVector[k+1:], sb.knotVector[k:])
+		sb.knotVector[k] = t
+	}
+	return nil
+}
+
+// UpdateControl replaces the control vertex with given no., the knot vector is unchanged
+func (sb *BSplineVertBuilder) UpdateControl(controlNo int, cv *ControlVertex) (err error) {
+	if controlNo < 0 || controlNo >= len(sb.vertices) {
+		return fmt.Errorf("control no. %v does not exist", controlNo)
+	}
+	sb.vertices[controlNo] = cv
+	return nil
+}
+
+// DeleteControl removes the control vertex with given no. Non-uniform knot vectors lose an inner domain knot around
+// the vertex' influence, end knots remain untouched
+func (sb *BSplineVertBuilder) DeleteControl(controlNo int) (err error) {
+	if controlNo < 0 || controlNo >= len(sb.vertices) {
+		return fmt.Errorf("control no. %v does not exist", controlNo)
+	}
+	sb.vertices = append(sb.vertices[:controlNo], sb.vertices[controlNo+1:]...)
+
+	if sb.uniform {
+		sb.knotVector = uniformKnotVector(len(sb.vertices), sb.clamped)
+	} else if prevCnt := len(sb.vertices) + 1; prevCnt <= bsplineDegree+1 {
+		// domain keeps less than 2 knots, only the first one survives
+		var tknots []float64
+		if prevCnt == bsplineDegree+1 {
+			tknots = []float64{sb.knotVector[bsplineDegree]}
+		}
+		sb.knotVector = extendKnotVector(tknots, len(sb.vertices), sb.clamped)
+	} else {
+		k := clampInt(controlNo+2, bsplineDegree+1, prevCnt-1)
+		sb.knotVector = append(sb.knotVector[:k], sb.knotVector[k+1:]...)
+	}
+	return nil
+}
+
+// clampInt limits i to the range lo ... hi
+func clampInt(i, lo, hi int) int {
+	if i < lo {
+		return lo
+	} else if i > hi {
+		return hi
+	}
+	return i
+}
+
+// blossom evaluates the blossom (polar form) of the polynomial piece on knot span [knotVector[k], knotVector[k+1]]
+// at (u1, u2, u3) using De Boor's algorithm with a different parameter on each level
+func (sb *BSplineVertBuilder) blossom(k int, us [bsplineDegree]float64) bendigo.Vec {
+	p := bsplineDegree
+	d := make([]bendigo.Vec, p+1)
+	for i := 0; i <= p; i++ {
+		d[i] = sb.vertices[k-p+i].loc
+	}
+	for r := 1; r <= p; r++ {
+		for j := p; j >= r; j-- {
+			tlo, thi := sb.knotVector[j+k-p], sb.knotVector[j+1+k-r]
+			alpha := 0.
+			if thi != tlo {
+				alpha = (us[r-1] - tlo) / (thi - tlo)
+			}
+			d[j] = d[j-1].Scale(1 - alpha).Add(d[j].Scale(alpha))
+		}
+	}
+	return d[p]
+}
+
+// segmentControls returns the bezier controls of a domain segment
+func (sb *BSplineVertBuilder) segmentControls(segmentNo int) [4]bendigo.Vec {
+	k := segmentNo + bsplineDegree
+	a, b := sb.knotVector[k], sb.knotVector[k+1]
+	return [4]bendigo.Vec{
+		sb.blossom(k, [3]float64{a, a, a}),
+		sb.blossom(k, [3]float64{a, a, b}),
+		sb.blossom(k, [3]float64{a, b, b}),
+		sb.blossom(k, [3]float64{b, b, b}),
+	}
+}
+
+// Bezier converts the b-spline into a bezier builder with the same domain knots
+func (sb *BSplineVertBuilder) Bezier() *BezierVertBuilder {
+	knots := sb.Knots()
+	segmCnt := knots.SegmentCnt()
+	if segmCnt == 0 {
+		return NewBezierVertBuilder(nil) // less than 4 vertices, domain is empty
+	}
+
+	controls := make([][4]bendigo.Vec, segmCnt)
+	for i := range controls {
+		controls[i] = sb.segmentControls(i)
+	}
+	vertices := make([]*EnexVertex, segmCnt+1)
+	start := controls[0][0]
+	vertices[0] = NewEnexVertexDep(start, start.InvertInPoint(controls[0][1]), controls[0][1], false, false, false)
+	for i := 1; i < segmCnt; i++ {
+		vertices[i] = NewEnexVertexDep(controls[i][0], controls[i-1][2], controls[i][1], false, false, false)
+	}
+	end := controls[segmCnt-1][3]
+	vertices[segmCnt] = NewEnexVertexDep(end, controls[segmCnt-1][2], end.InvertInPoint(controls[segmCnt-1][2]), false, false, false)
+
+	return NewBezierVertBuilder(knots.External(), vertices...)
+}
+
+func (sb *BSplineVertBuilder) Canonical() *CanonicalSpline {
+	return sb.Bezier().Canonical()
+}
+
+func (sb *BSplineVertBuilder) Spline() bendigo.Spline {
+	return sb.Canonical()
+}
+
+func (sb *BSplineVertBuilder) LinApproximate(fromSegmentNo, toSegmentNo int, consumer bendigo.LineConsumer, linaxParams *bendigo.LinaxParams) {
+	sb.Bezier().LinApproximate(fromSegmentNo, toSegmentNo, consumer, linaxParams)
+}
+
+func (sb *BSplineVertBuilder) LinaxSpline(linaxParams *bendigo.LinaxParams) *bendigo.LinaxSpline {
+	return bendigo.BuildLinaxSpline(sb, linaxParams)
+}
@@ -0,0 +1,193 @@
+package cubic
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"testing"
+)
+
+func createBSplineVertices() []*ControlVertex {
+	return []*ControlVertex{
+		NewControlVertex(bendigo.NewVec(0, 0)),
+		NewControlVertex(bendigo.NewVec(1, 2)),
+		NewControlVertex(bendigo.NewVec(3, 2)),
+		NewControlVertex(bendigo.NewVec(4, 0)),
+		NewControlVertex(bendigo.NewVec(6, 1)),
+	}
+}
+
+// coxDeBoor evaluates the b-spline at t directly by the Cox-de Boor recursion of the basis functions
+func coxDeBoor(knotVector []float64, vertices []*ControlVertex, t float64) bendigo.Vec {
+	var basis func(i, p int) float64
+	basis = func(i, p int) float64 {
+		if p == 0 {
+			if t == knotVector[len(knotVector)-bsplineDegree-1] { // end of domain belongs to the last span
+				if knotVector[i] < t && t <= knotVector[i+1] {
+					return 1
+				}
+			} else if knotVector[i] <= t && t < knotVector[i+1] {
+				return 1
+			}
+			return 0
+		}
+		b := 0.
+		if knotVector[i+p] != knotVector[i] {
+			b += (t - knotVector[i]) / (knotVector[i+p] - knotVector[i]) * basis(i, p-1)
+		}
+		if knotVector[i+p+1] != knotVector[i+1] {
+			b += (knotVector[i+p+1] - t) / (knotVector[i+p+1] - knotVector[i+1]) * basis(i+1, p-1)
+		}
+		return b
+	}
+	v := bendigo.NewZeroVec(vertices[0].loc.Dim())
+	for i, cv := range vertices {
+		v = v.Add(cv.loc.Scale(basis(i, bsplineDegree)))
+	}
+	return v
+}
+
+func assertMatchesCoxDeBoor(t *testing.T, sb *BSplineVertBuilder) {
+	spline := sb.Spline()
+	tstart, tend := spline.Knots().Tstart(), spline.Knots().Tend()
+	for i := 0; i <= 40; i++ {
+		at := tstart + float64(i)/40*(tend-tstart)
+		bendigotest.AssertVecInDelta(t, coxDeBoor(sb.knotVector, sb.vertices, at), spline.At(at), "")
+	}
+}
+
+func TestBSplineVertBuilder_Uniform(t *testing.T) {
+	vertices := createBSplineVertices()
+	sb := NewBSplineVertBuilder(nil, false, vertices...)
+	assert.Equal(t, 3, sb.Knots().KnotCnt())
+	assert.Equal(t, 5, sb.ControlCnt(), "2 controls more than knots")
+	assert.Equal(t, vertices[4], sb.Control(4))
+	assert.Nil(t, sb.Control(5))
+	_, isVertBuilder := interface{}(sb).(bendigo.SplineVertBuilder)
+	assert.False(t, isVertBuilder, "controls are not indexed by knot no.")
+	assert.Equal(t, []float64{-3, -2, -1, 0, 1, 2, 3, 4, 5}, sb.KnotVector())
+
+	// uniform cubic b-spline starts at (P0 + 4*P1 + P2) / 6
+	expected := vertices[0].loc.Add(vertices[1].loc.Scale(4)).Add(vertices[2].loc).Scale(1. / 6)
+	AssertSplineAt(t, sb.Spline(), 0, expected)
+	assertMatchesCoxDeBoor(t, sb)
+}
+
+func TestBSplineVertBuilder_Clamped(t *testing.T) {
+	vertices := createBSplineVertices()
+	sb := NewBSplineVertBuilder(nil, true, vertices...)
+	assert.Equal(t, []float64{0, 0, 0, 0, 1, 2, 2, 2, 2}, sb.KnotVector())
+	AssertSplineAt(t, sb.Spline(), 0, vertices[0].loc)
+	AssertSplineAt(t, sb.Spline(), 2, vertices[4].loc)
+	assertMatchesCoxDeBoor(t, sb)
+
+	// clamped with 4 vertices is a single bezier segment
+	bez := NewBSplineVertBuilder(nil, true, vertices[:4]...).Bezier()
+	assert.Equal(t, 2, bez.Knots().KnotCnt())
+	bendigotest.AssertVecInDelta(t, vertices[1].loc, bez.vertices[0].Exit(), "first control")
+	bendigotest.AssertVecInDelta(t, vertices[2].loc, bez.vertices[1].Entry(), "second control")
+}
+
+func TestBSplineVertBuilder_NonUniform(t *testing.T) {
+	vertices := createBSplineVertices()
+	sb := NewBSplineVertBuilder([]float64{0, 0.5, 2}, false, vertices...)
+	assert.Equal(t, []float64{-1.5, -1, -0.5, 0, 0.5, 2, 3.5, 5, 6.5}, sb.KnotVector())
+	assert.Equal(t, []float64{0, 0.5, 2}, sb.Knots().External())
+	assertMatchesCoxDeBoor(t, sb)
+
+	sb = NewBSplineVertBuilder([]float64{0, 0.5, 2}, true, vertices...)
+	AssertSplineAt(t, sb.Spline(), 0, vertices[0].loc)
+	AssertSplineAt(t, sb.Spline(), 2, vertices[4].loc)
+	assertMatchesCoxDeBoor(t, sb)
+
+	sb = NewBSplineVertBuilderByKnotVector([]float64{0, 0, 1, 2, 3, 4, 5, 5, 5}, vertices...)
+	assert.Equal(t, []float64{2, 3, 4}, sb.Knots().External())
+	assertMatchesCoxDeBoor(t, sb)
+}
+
+func TestBSplineVertBuilder_Bezier(t *testing.T) {
+	for _, sb := range []*BSplineVertBuilder{
+		NewBSplineVertBuilder(nil, false, createBSplineVertices()...),
+		NewBSplineVertBuilder([]float64{0, 1, 3}, true, createBSplineVertices()...),
+	} {
+		bendigotest.AssertSplinesEqual(t, sb.Bezier().Spline(), sb.Canonical(), 50)
+		lc := bendigo.NewLineToSliceCollector()
+		sb.LinApproximate(0, sb.Knots().SegmentCnt()-1, lc, bendigo.NewLinaxParams(0.02))
+		AssertApproxStartPointsMatchSpline(t, lc.Lines, sb.Spline())
+		linax := sb.LinaxSpline(bendigo.NewLinaxParams(0.02))
+		bendigotest.AssertVecInDelta(t, sb.Spline().At(0), linax.At(0), "linax start")
+	}
+
+	// less than 4 vertices: empty domain
+	sb := NewBSplineVertBuilder(nil, false, createBSplineVertices()[:3]...)
+	assert.Equal(t, 0, sb.Bezier().Knots().SegmentCnt())
+}
+
+func TestBSplineVertBuilder_AddDeleteControl(t *testing.T) {
+	sb := NewBSplineVertBuilder(nil, true, createBSplineVertices()...)
+	assert.Nil(t, sb.AddControl(5, NewControlVertex(bendigo.NewVec(7, 3))))
+	assert.Equal(t, 4, sb.Knots().KnotCnt())
+	assert.Equal(t, []float64{0, 0, 0, 0, 1, 2, 3, 3, 3, 3}, sb.KnotVector())
+	AssertSplineAt(t, sb.Spline(), 3, bendigo.NewVec(7, 3))
+	assert.NotNil(t, sb.AddControl(7, NewControlVertex(bendigo.NewVec(0, 0))))
+	assert.Nil(t, sb.UpdateControl(5, NewControlVertex(bendigo.NewVec(8, 3))))
+	AssertSplineAt(t, sb.Spline(), 3, bendigo.NewVec(8, 3))
+	assert.NotNil(t, sb.UpdateControl(6, NewControlVertex(bendigo.NewVec(0, 0))))
+
+	assert.Nil(t, sb.DeleteControl(0))
+	assert.Equal(t, 3, sb.Knots().KnotCnt())
+	AssertSplineAt(t, sb.Spline(), 0, bendigo.NewVec(1, 2))
+	assert.NotNil(t, sb.DeleteControl(5))
+
+	// unclamped: new knot splits a domain span, no multiple knots
+	sb = NewBSplineVertBuilder([]float64{0, 1, 3}, false, createBSplineVertices()...)
+	assert.Nil(t, sb.AddControl(2, NewControlVertex(bendigo.NewVec(2, 3))))
+	assert.Equal(t, []float64{-3, -2, -1, 0, 0.5, 1, 3, 5, 7, 9}, sb.KnotVector())
+	assertMatchesCoxDeBoor(t, sb)
+	assert.Nil(t, sb.DeleteControl(2))
+	assert.Equal(t, []float64{-3, -2, -1, 0, 1, 3, 5, 7, 9}, sb.KnotVector())
+	assertMatchesCoxDeBoor(t, sb)
+}
+
+func TestBSplineVertBuilder_AddDeleteControl_NonUniformClamped(t *testing.T) {
+	sb := NewBSplineVertBuilder([]float64{0, 1, 3}, true, createBSplineVertices()...)
+	first, last := NewControlVertex(bendigo.NewVec(-1, -1)), NewControlVertex(bendigo.NewVec(7, 3))
+	assert.Nil(t, sb.AddControl(0, first))
+	assert.Equal(t, []float64{0, 0, 0, 0, 0.5, 1, 3, 3, 3, 3}, sb.KnotVector())
+	assert.Nil(t, sb.AddControl(6, last))
+	assert.Equal(t, []float64{0, 0, 0, 0, 0.5, 1, 2, 3, 3, 3, 3}, sb.KnotVector())
+	assert.Equal(t, []float64{0, 0.5, 1, 2, 3}, sb.Knots().External())
+	AssertSplineAt(t, sb.Spline(), 0, first.loc)
+	AssertSplineAt(t, sb.Spline(), 3, last.loc)
+	assertMatchesCoxDeBoor(t, sb)
+
+	assert.Nil(t, sb.DeleteControl(6))
+	assert.Nil(t, sb.DeleteControl(0))
+	assert.Equal(t, []float64{0, 0, 0, 0, 1, 3, 3, 3, 3}, sb.KnotVector())
+	AssertSplineAt(t, sb.Spline(), 0, bendigo.NewVec(0, 0))
+	assertMatchesCoxDeBoor(t, sb)
+
+	// shrink to a single domain knot and grow again
+	for sb.Knots().KnotCnt() > 1 {
+		assert.Nil(t, sb.DeleteControl(1))
+	}
+	assert.Equal(t, []float64{0, 0, 0, 0, 0, 0, 0}, sb.KnotVector())
+	assert.Nil(t, sb.AddControl(3, last))
+	assert.Equal(t, []float64{0, 0, 0, 0, 1, 1, 1, 1}, sb.KnotVector())
+	AssertSplineAt(t, sb.Spline(), 1, last.loc)
+}
+
+func TestBSplineVertBuilder_SetKnotVector(t *testing.T) {
+	sb := NewBSplineVertBuilder(nil, false, createBSplineVertices()...)
+	assert.NotNil(t, sb.SetKnotVector([]float64{0, 0, 0, 0, 1, 2, 2, 2}), "too short")
+	assert.NotNil(t, sb.SetKnotVector([]float64{0, 0, 0, 0, 2, 1, 2, 2, 2}), "decreasing")
+
+	assert.Nil(t, sb.SetKnotVector([]float64{0, 0, 0, 0, 0.5, 2, 2, 2, 2}))
+	assert.True(t, sb.Clamped())
+	assert.Equal(t, []float64{0, 0.5, 2}, sb.Knots().External())
+	assertMatchesCoxDeBoor(t, sb)
+
+	// knots are not recreated uniformly anymore
+	assert.Nil(t, sb.AddControl(5, NewControlVertex(bendigo.NewVec(7, 3))))
+	assert.Equal(t, []float64{0, 0, 0, 0, 0.5, 1.25, 2, 2, 2, 2}, sb.KnotVector())
+}
@@ -1,8 +1,12 @@
 package cubic
 
 import (
+	"errors"
+	"fmt"
 	"github.com/walpod/bendigo"
 	"gonum.org/v1/gonum/mat"
+	"math"
+	"sort"
 )
 
 // cubic polynomial
@@ -18,6 +22,50 @@ func (cb *CubicPoly) At(u float64) float64 {
 	return cb.a + u*(cb.b+u*(cb.c+cb.d*u))
 }
 
+// Deriv calculates the derivative of given order at u
+func (cb *CubicPoly) Deriv(u float64, order int) float64 {
+	switch order {
+	case 0:
+		return cb.At(u)
+	case 1:
+		return cb.b + u*(2*cb.c+3*cb.d*u)
+	case 2:
+		return 2*cb.c + 6*cb.d*u
+	case 3:
+		return 6 * cb.d
+	default:
+		return 0
+	}
+}
+
+// Range calculates the minimum and maximum value within [ufrom, uto] using the roots of the derivative
+func (cb *CubicPoly) Range(ufrom, uto float64) (min, max float64) {
+	min, max = cb.At(ufrom), cb.At(uto)
+	if min > max {
+		min, max = max, min
+	}
+	for _, u := range solveQuadratic(cb.b, 2*cb.c, 3*cb.d) {
+		if u > ufrom && u < uto {
+			v := cb.At(u)
+			min, max = math.Min(min, v), math.Max(max, v)
+		}
+	}
+	return
+}
+
+// Roots calculates all u within [ufrom, uto] with value 0, in ascending order
+func (cb *CubicPoly) Roots(ufrom, uto float64) []float64 {
+	const eps = 1e-9
+	roots := make([]float64, 0, 3)
+	for _, u := range solveCubic(cb.a, cb.b, cb.c, cb.d) {
+		if u >= ufrom-eps && u <= uto+eps {
+			roots = append(roots, math.Min(math.Max(u, ufrom), uto))
+		}
+	}
+	sort.Float64s(roots)
+	return roots
+}
+
 /*func (cb *CubicPoly) Fn() func(float64) float64 {
 	return func(u float64) float64 {
 		return cb.At(u)
@@ -46,6 +94,57 @@ func (cb *CubicPolies) At(u float64) bendigo.Vec {
 	return p
 }
 
+func (cb *CubicPolies) Deriv(u float64, order int) bendigo.Vec {
+	dim := len(cb.cubs)
+	p := make(bendigo.Vec, dim)
+	for d := 0; d < dim; d++ {
+		p[d] = cb.cubs[d].Deriv(u, order)
+	}
+	return p
+}
+
+// BBox calculates the exact bounding box within [ufrom, uto]
+func (cb *CubicPolies) BBox(ufrom, uto float64) *bendigo.BBox {
+	dim := len(cb.cubs)
+	bb := &bendigo.BBox{Min: bendigo.NewZeroVec(dim), Max: bendigo.NewZeroVec(dim)}
+	for d := 0; d < dim; d++ {
+		bb.Min[d], bb.Max[d] = cb.cubs[d].Range(ufrom, uto)
+	}
+	return bb
+}
+
+// Project projects the cubic polynomials onto normal and subtracts offset, yielding normal . cb(u) - offset
+func (cb *CubicPolies) Project(normal bendigo.Vec, offset float64) CubicPoly {
+	var pr CubicPoly
+	for d, cub := range cb.cubs {
+		pr.a += normal[d] * cub.a
+		pr.b += normal[d] * cub.b
+		pr.c += normal[d] * cub.c
+		pr.d += normal[d] * cub.d
+	}
+	pr.a -= offset
+	return pr
+}
+
+// distDerivPoly returns the coefficients of the quintic polynomial (cb(u) - p) . cb'(u), which is
+// half the derivative of the squared distance between p and the point at u
+func (cb *CubicPolies) distDerivPoly(p bendigo.Vec) []float64 {
+	coefs := make([]float64, 6)
+	for d, cub := range cb.cubs {
+		pc := [4]float64{cub.a - p[d], cub.b, cub.c, cub.d}
+		dc := [3]float64{cub.b, 2 * cub.c, 3 * cub.d}
+		for i := range pc {
+			for j := range dc {
+				coefs[i+j] += pc[i] * dc[j]
+			}
+		}
+	}
+	return coefs
+}
+
+// continuityTolerance is the maximal distance between end and start of consecutive segments to be treated as joined
+const continuityTolerance = 1e-9
+
 type CanonicalSpline struct {
 	knots  bendigo.Knots
 	cubics []CubicPolies
@@ -111,6 +210,13 @@ func (sp *CanonicalSpline) Knots() bendigo.Knots {
 	return sp.knots
 }
 
+func (sp *CanonicalSpline) Dim() int {
+	if len(sp.cubics) == 0 {
+		return 0
+	}
+	return sp.cubics[0].Dim()
+}
+
 func (sp *CanonicalSpline) At(t float64) bendigo.Vec {
 	if len(sp.cubics) == 0 {
 		return nil //return make(bendigo.Vector, sp.dim) ... point (0,0,...0)
@@ -123,3 +229,192 @@ func (sp *CanonicalSpline) At(t float64) bendigo.Vec {
 		return sp.cubics[segmentNo].At(u)
 	}
 }
+
+func (sp *CanonicalSpline) AtInto(ts []float64, dst []float64) (err error) {
+	dim := sp.Dim()
+	if len(dst) < len(ts)*dim {
+		return fmt.Errorf("destination has length %v, but %v values are required", len(dst), len(ts)*dim)
+	}
+
+	cursor := bendigo.NewSegmentCursor(sp.knots)
+	for i, t := range ts {
+		segmentNo, u, err := cursor.MapToSegment(t)
+		if err != nil {
+			return err
+		}
+		cubs := sp.cubics[segmentNo].cubs
+		for d := 0; d < dim; d++ {
+			dst[i*dim+d] = cubs[d].At(u)
+		}
+	}
+	return nil
+}
+
+// Bezier converts the canonical spline into an editable bezier builder with the same knots. Tangent discontinuities
+// between segments are retained by independent entry and exit controls, discontinuities of location yield an error
+func (sp *CanonicalSpline) Bezier() (*BezierVertBuilder, error) {
+	segmCnt := len(sp.cubics)
+	if segmCnt == 0 {
+		return NewBezierVertBuilder(sp.knots.External()), nil
+	}
+
+	// bezier controls of each segment
+	controls := make([][4]bendigo.Vec, segmCnt)
+	for i := range sp.cubics {
+		cubs := sp.cubics[i].cubs
+		dim := len(cubs)
+		for j := range controls[i] {
+			controls[i][j] = bendigo.NewZeroVec(dim)
+		}
+		for d, cub := range cubs {
+			controls[i][0][d] = cub.a
+			controls[i][1][d] = cub.a + cub.b/3
+			controls[i][2][d] = cub.a + (2*cub.b+cub.c)/3
+			controls[i][3][d] = cub.a + cub.b + cub.c + cub.d
+		}
+		if i > 0 && controls[i-1][3].Sub(controls[i][0]).Len() > continuityTolerance {
+			return nil, fmt.Errorf("segments %v and %v are not joined, location is discontinuous", i-1, i)
+		}
+	}
+
+	// unused controls at start and end are mirrored
+	vertices := make([]*EnexVertex, segmCnt+1)
+	start := controls[0][0]
+	vertices[0] = NewEnexVertexDep(start, start.InvertInPoint(controls[0][1]), controls[0][1], false, false, false)
+	for i := 1; i < segmCnt; i++ {
+		vertices[i] = NewEnexVertexDep(controls[i][0], controls[i-1][2], controls[i][1], false, false, false)
+	}
+	end := controls[segmCnt-1][3]
+	vertices[segmCnt] = NewEnexVertexDep(end, controls[segmCnt-1][2], end.InvertInPoint(controls[segmCnt-1][2]), false, false, false)
+
+	return NewBezierVertBuilder(sp.knots.External(), vertices...), nil
+}
+
+// Hermite converts the canonical spline into an editable hermite builder with the same knots, tangents are
+// derivatives by t. Discontinuities are handled as in Bezier
+func (sp *CanonicalSpline) Hermite() (*HermiteVertBuilder, error) {
+	bezier, err := sp.Bezier()
+	if err != nil {
+		return nil, err
+	}
+	return bezier.Hermite(), nil
+}
+
+// Deriv calculates the derivative of given order at parameter t
+func (sp *CanonicalSpline) Deriv(t float64, order int) bendigo.Vec {
+	if len(sp.cubics) == 0 || order < 0 {
+		return nil
+	}
+
+	segmentNo, u, err := sp.knots.MapToSegment(t)
+	if err != nil {
+		return nil
+	} else {
+		return sp.cubics[segmentNo].Deriv(u, order).Scale(bendigo.DerivScale(sp.knots, segmentNo, order))
+	}
+}
+
+// SegmentBBox calculates the exact bounding box of a segment
+func (sp *CanonicalSpline) SegmentBBox(segmentNo int) (*bendigo.BBox, error) {
+	if segmentNo < 0 || segmentNo >= len(sp.cubics) {
+		return nil, fmt.Errorf("segment with no. %v doesn't exist", segmentNo)
+	}
+	return sp.cubics[segmentNo].BBox(0, 1), nil
+}
+
+// BBox calculates the exact bounding box of the spline, nil if spline is empty
+func (sp *CanonicalSpline) BBox() *bendigo.BBox {
+	var bb *bendigo.BBox
+	for i := range sp.cubics {
+		bb = bb.Union(sp.cubics[i].BBox(0, 1))
+	}
+	return bb
+}
+
+// ClosestPoint finds the point q on the spline with minimal distance to p and returns its parameter t.
+// Segments are examined in order of their bounding box distance and pruned if they can't contain a closer point
+func (sp *CanonicalSpline) ClosestPoint(p bendigo.Vec) (t float64, q bendigo.Vec, dist float64, err error) {
+	if len(sp.cubics) == 0 {
+		return 0, nil, 0, errors.New("closest point of empty spline doesn't exist")
+	}
+
+	// sort segments by distance of their bounding boxes to p
+	type segmentDist struct {
+		segmentNo int
+		dist      float64
+	}
+	sds := make([]segmentDist, len(sp.cubics))
+	for i := range sp.cubics {
+		sds[i] = segmentDist{i, sp.cubics[i].BBox(0, 1).Dist(p)}
+	}
+	sort.Slice(sds, func(i, j int) bool { return sds[i].dist < sds[j].dist })
+
+	dist = math.Inf(1)
+	for _, sd := range sds {
+		if sd.dist > dist {
+			break
+		}
+		cubs := &sp.cubics[sd.segmentNo]
+		// candidates: segment ends and roots of the derivative of squared distance
+		for _, u := range append([]float64{0, 1}, polyRoots(cubs.distDerivPoly(p), 0, 1)...) {
+			cq := cubs.At(u)
+			if cd := cq.Sub(p).Len(); cd < dist {
+				tstart, tend, _ := bendigo.SegmentTrange(sp.knots, sd.segmentNo)
+				t, q, dist = tstart+u*(tend-tstart), cq, cd
+			}
+		}
+	}
+	return t, q, dist, nil
+}
+
+// HyperplaneIntersections calculates the parameters t of all crossings with the hyperplane through p
+// perpendicular to normal (a line in 2D, a plane in 3D), in ascending order
+func (sp *CanonicalSpline) HyperplaneIntersections(p, normal bendigo.Vec) []float64 {
+	ts := make([]float64, 0)
+	offset := normal.Dot(p)
+	for segmentNo := range sp.cubics {
+		pr := sp.cubics[segmentNo].Project(normal, offset)
+		tstart, tend, _ := bendigo.SegmentTrange(sp.knots, segmentNo)
+		for _, u := range pr.Roots(0, 1) {
+			t := tstart + u*(tend-tstart)
+			if len(ts) == 0 || t-ts[len(ts)-1] > 1e-9 { // knots are shared by adjacent segments
+				ts = append(ts, t)
+			}
+		}
+	}
+	return ts
+}
+
+// LineIntersections calculates the parameters t of all crossings with the infinite line through p in direction dir,
+// for splines of dimension 2
+func (sp *CanonicalSpline) LineIntersections(p, dir bendigo.Vec) ([]float64, error) {
+	if len(sp.cubics) > 0 && sp.cubics[0].Dim() != 2 || p.Dim() != 2 || dir.Dim() != 2 {
+		return nil, errors.New("line intersections require dimension 2")
+	}
+	return sp.HyperplaneIntersections(p, bendigo.NewVec(-dir[1], dir[0])), nil
+}
+
+// RayIntersections calculates the parameters t of all crossings with the ray starting at p in direction dir,
+// for splines of dimension 2
+func (sp *CanonicalSpline) RayIntersections(p, dir bendigo.Vec) ([]float64, error) {
+	ts, err := sp.LineIntersections(p, dir)
+	if err != nil {
+		return nil, err
+	}
+	rts := make([]float64, 0, len(ts))
+	for _, t := range ts {
+		if sp.At(t).Sub(p).Dot(dir) >= 0 {
+			rts = append(rts, t)
+		}
+	}
+	return rts, nil
+}
+
+// PlaneIntersections calculates the parameters t of all crossings with the plane through p perpendicular to normal,
+// for splines of dimension 3
+func (sp *CanonicalSpline) PlaneIntersections(p, normal bendigo.Vec) ([]float64, error) {
+	if len(sp.cubics) > 0 && sp.cubics[0].Dim() != 3 || p.Dim() != 3 || normal.Dim() != 3 {
+		return nil, errors.New("plane intersections require dimension 3")
+	}
+	return sp.HyperplaneIntersections(p, normal), nil
+}
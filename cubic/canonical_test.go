@@ -1,8 +1,13 @@
 package cubic
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"math"
+	"math/rand"
+	"sort"
 	"testing"
 )
 
@@ -57,3 +62,220 @@ func TestCanonicalSpline(t *testing.T) {
 	ts, te = canon.Knots().Tstart(), canon.Knots().Tend()
 	assert.Greaterf(t, ts, te, "empty knots: tstart %v must be greater than tend %v", ts, te)
 }
+
+func TestCanonicalSpline_Deriv(t *testing.T) {
+	canon := createCanonParabola00to11()
+	for _, u := range []float64{0, 0.25, 0.5, 1} {
+		bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 2*u), canon.Deriv(u, 1), "velocity of parabola")
+		bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 2), canon.Deriv(u, 2), "acceleration of parabola")
+		bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), canon.Deriv(u, 3), "jerk of parabola")
+	}
+	bendigotest.AssertVecInDelta(t, canon.At(0.5), canon.Deriv(0.5, 0), "derivative of order 0 equals At")
+	assert.Nil(t, canon.Deriv(1.5, 1), "out of domain")
+
+	// non-uniform: u = t/2, derivatives are scaled by 1/2 per order
+	canon = NewCanonicalSpline([]float64{0, 2},
+		NewCubicPolies(NewCubicPoly(0, 1, 0, 0), NewCubicPoly(0, 0, 1, 0)))
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0.5, 0.5), canon.Deriv(1, 1), "velocity of non-uniform parabola")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0.5), canon.Deriv(1, 2), "acceleration of non-uniform parabola")
+
+	// non-uniform hermite: velocity on diagonal matches exit tangent
+	herm := createNonUniHermDiag00to11().Canonical()
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 1), herm.Deriv(0, 1), "velocity at start equals exit tangent")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 1), herm.Deriv(herm.Knots().Tend(), 1), "velocity at end equals entry tangent")
+}
+
+func TestCanonicalSpline_Curvature(t *testing.T) {
+	// curvature is a geometric property, independent of the knots
+	canon := createCanonParabola00to11()
+	nucanon := NewCanonicalSpline([]float64{0, 2},
+		NewCubicPolies(NewCubicPoly(0, 1, 0, 0), NewCubicPoly(0, 0, 1, 0)))
+	for _, u := range []float64{0, 0.3, 0.5, 1} {
+		k, err := bendigo.Curvature(canon, u)
+		assert.Nil(t, err)
+		// y = x^2: k = 2 / (1 + 4x^2)^(3/2)
+		assert.InDeltaf(t, 2/math.Pow(1+4*u*u, 1.5), k, delta, "curvature of parabola at %v", u)
+		nuk, _ := bendigo.Curvature(nucanon, 2*u)
+		assert.InDeltaf(t, k, nuk, delta, "curvature of non-uniform parabola at %v", 2*u)
+	}
+}
+
+func TestCanonicalSpline_BBox(t *testing.T) {
+	canon := createDoubleCanonParabola00to11to22()
+	bb := canon.BBox()
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), bb.Min, "minimum")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(2, 2), bb.Max, "maximum")
+
+	// parabola y = (2u-1)^2 with minimum inside the segment
+	canon = NewCanonicalSpline(nil, NewCubicPolies(NewCubicPoly(0, 1, 0, 0), NewCubicPoly(1, -4, 4, 0)))
+	bb, err := canon.SegmentBBox(0)
+	assert.Nil(t, err)
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), bb.Min, "minimum at vertex of parabola")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 1), bb.Max, "maximum at ends of parabola")
+	_, err = canon.SegmentBBox(1)
+	assert.NotNil(t, err, "segment doesn't exist")
+
+	assert.Nil(t, NewCanonicalSpline(nil).BBox(), "empty spline has no bounding box")
+}
+
+func TestCanonicalSpline_ClosestPoint(t *testing.T) {
+	canon := createNonUniHermDiag00to11().Canonical()
+	atT, q, dist, err := canon.ClosestPoint(bendigo.NewVec(1, 0))
+	assert.Nil(t, err)
+	assert.InDelta(t, math.Sqrt2/2, atT, delta, "parameter of closest point on non-uniform diagonal")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0.5, 0.5), q, "closest point on diagonal")
+	assert.InDelta(t, math.Sqrt(0.5), dist, delta, "distance to diagonal")
+
+	// beyond the end of the diagonal
+	atT, q, _, _ = canon.ClosestPoint(bendigo.NewVec(3, 2))
+	assert.InDelta(t, math.Sqrt2, atT, delta, "end of diagonal is closest")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 1), q, "end of diagonal is closest")
+
+	// y = x^2: closest point to (0, 1) at x = sqrt(1/2)
+	canon = createDoubleCanonParabola00to11to22()
+	atT, q, dist, _ = canon.ClosestPoint(bendigo.NewVec(0, 1))
+	assert.InDelta(t, math.Sqrt(0.5), atT, delta, "parameter of closest point on parabola")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(math.Sqrt(0.5), 0.5), q, "closest point on parabola")
+	assert.InDelta(t, math.Sqrt(0.75), dist, delta, "distance to parabola")
+
+	// compare with dense sampling
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		p := bendigo.NewVec(rnd.Float64()*4-1, rnd.Float64()*4-1)
+		_, _, dist, _ = canon.ClosestPoint(p)
+		for j := 0; j <= 200; j++ {
+			assert.LessOrEqual(t, dist, canon.At(float64(j)/100).Sub(p).Len()+delta, "no sample may be closer")
+		}
+	}
+
+	_, _, _, err = NewCanonicalSpline(nil).ClosestPoint(bendigo.NewVec(0, 0))
+	assert.NotNil(t, err, "empty spline has no closest point")
+}
+
+func TestCanonicalSpline_LineIntersections(t *testing.T) {
+	canon := createDoubleCanonParabola00to11to22()
+	ts, err := canon.LineIntersections(bendigo.NewVec(0, 0.5), bendigo.NewVec(1, 0))
+	assert.Nil(t, err)
+	assert.InDeltaSlice(t, []float64{math.Sqrt(0.5)}, ts, delta, "horizontal line crosses first parabola")
+	ts, _ = canon.LineIntersections(bendigo.NewVec(1, 5), bendigo.NewVec(0, -1))
+	assert.InDeltaSlice(t, []float64{1}, ts, delta, "vertical line crosses at knot, reported once")
+	ts, _ = canon.LineIntersections(bendigo.NewVec(0, 0), bendigo.NewVec(1, 1))
+	assert.InDeltaSlice(t, []float64{0, 1, 2}, ts, delta, "diagonal crosses at all knots")
+	ts, _ = canon.LineIntersections(bendigo.NewVec(0, 3), bendigo.NewVec(1, 0))
+	assert.Empty(t, ts, "line above spline")
+
+	ts, err = canon.RayIntersections(bendigo.NewVec(1.5, 0.5), bendigo.NewVec(1, 0))
+	assert.Nil(t, err)
+	assert.Empty(t, ts, "crossing is behind ray")
+	ts, _ = canon.RayIntersections(bendigo.NewVec(1.5, 0.5), bendigo.NewVec(-1, 0))
+	assert.InDeltaSlice(t, []float64{math.Sqrt(0.5)}, ts, delta, "crossing is in front of ray")
+
+	_, err = canon.PlaneIntersections(bendigo.NewVec(0, 0, 0), bendigo.NewVec(0, 0, 1))
+	assert.NotNil(t, err, "planes require dimension 3")
+}
+
+func TestCanonicalSpline_PlaneIntersections(t *testing.T) {
+	// (u, u^2, u^3) followed by a straight line
+	canon := NewCanonicalSpline([]float64{0, 1, 3},
+		NewCubicPolies(NewCubicPoly(0, 1, 0, 0), NewCubicPoly(0, 0, 1, 0), NewCubicPoly(0, 0, 0, 1)),
+		NewCubicPolies(NewCubicPoly(1, 1, 0, 0), NewCubicPoly(1, -2, 0, 0), NewCubicPoly(1, 0, 0, 0)))
+	ts, err := canon.PlaneIntersections(bendigo.NewVec(0, 0, 0.125), bendigo.NewVec(0, 0, 1))
+	assert.Nil(t, err)
+	assert.InDeltaSlice(t, []float64{0.5}, ts, delta, "plane z = 0.125")
+	ts, _ = canon.PlaneIntersections(bendigo.NewVec(0, 0.5, 0), bendigo.NewVec(0, 1, 0))
+	assert.InDeltaSlice(t, []float64{math.Sqrt(0.5), 1.5}, ts, delta, "plane y = 0.5, non-uniform knots")
+
+	_, err = canon.LineIntersections(bendigo.NewVec(0, 0), bendigo.NewVec(1, 0))
+	assert.NotNil(t, err, "lines require dimension 2")
+}
+
+func AssertAtIntoMatchesAt(t *testing.T, spline bendigo.BatchSpline, sampleCnt int) {
+	ts := make([]float64, sampleCnt)
+	ts[0], ts[1] = spline.Knots().Tstart(), spline.Knots().Tend()
+	rnd := rand.New(rand.NewSource(1))
+	for i := 2; i < sampleCnt; i++ {
+		ts[i] = spline.Knots().Tstart() + rnd.Float64()*(spline.Knots().Tend()-spline.Knots().Tstart())
+	}
+	sorted := append([]float64{}, ts...)
+	sort.Float64s(sorted)
+
+	dim := spline.Dim()
+	dst := make([]float64, sampleCnt*dim)
+	for _, pts := range [][]float64{ts, sorted} {
+		err := spline.AtInto(pts, dst)
+		assert.Nil(t, err)
+		for i, atT := range pts {
+			bendigotest.AssertVecInDelta(t, spline.At(atT), dst[i*dim:(i+1)*dim], fmt.Sprintf("batch must match At(%v)", atT))
+		}
+	}
+	assert.NotNil(t, spline.AtInto(ts, dst[:len(dst)-1]), "destination too short")
+	assert.NotNil(t, spline.AtInto([]float64{spline.Knots().Tend() + 1}, dst), "out of domain")
+}
+
+func TestCanonicalSpline_AtInto(t *testing.T) {
+	AssertAtIntoMatchesAt(t, createDoubleCanonParabola00to11to22(), 100)
+	AssertAtIntoMatchesAt(t, createNonUniHermDiag00to11().Canonical(), 100)
+
+	canon := createDoubleCanonParabola00to11to22()
+	ts := []float64{0, 0.5, 1, 1.5, 2}
+	dst := make([]float64, len(ts)*canon.Dim())
+	allocs := testing.AllocsPerRun(10, func() {
+		_ = canon.AtInto(ts, dst)
+	})
+	assert.Equal(t, 0., allocs, "batch evaluation may not allocate")
+}
+
+func TestCanonicalSpline_Bezier(t *testing.T) {
+	canon := createDoubleCanonParabola00to11to22()
+	bezier, err := canon.Bezier()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, bezier.knots.KnotCnt(), "one vertex per knot")
+	bendigotest.AssertSplinesEqual(t, canon, bezier.Spline(), 100)
+
+	// non-uniform
+	canon = createNonUniHermDiag00to11().Canonical()
+	bezier, _ = canon.Bezier()
+	assert.Equal(t, canon.knots.External(), bezier.knots.External(), "knots must be retained")
+	bendigotest.AssertSplinesEqual(t, canon, bezier.Spline(), 100)
+
+	// tangent discontinuity: corner at (1,0)
+	canon = NewCanonicalSpline(nil,
+		NewCubicPolies(NewCubicPoly(0, 1, 0, 0), NewCubicPoly(0, 0, 0, 0)),
+		NewCubicPolies(NewCubicPoly(1, 0, 0, 0), NewCubicPoly(0, 1, 0, 0)))
+	bezier, err = canon.Bezier()
+	assert.Nil(t, err)
+	bendigotest.AssertSplinesEqual(t, canon, bezier.Spline(), 100)
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(2./3, 0), bezier.BezierVertex(1).Entry(), "entry along first segment")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 1./3), bezier.BezierVertex(1).Exit(), "exit along second segment")
+
+	// location discontinuity
+	canon = NewCanonicalSpline(nil,
+		NewCubicPolies(NewCubicPoly(0, 1, 0, 0), NewCubicPoly(0, 0, 0, 0)),
+		NewCubicPolies(NewCubicPoly(2, 0, 0, 0), NewCubicPoly(0, 1, 0, 0)))
+	_, err = canon.Bezier()
+	assert.NotNil(t, err, "segments are not joined")
+
+	bezier, err = NewCanonicalSpline(nil).Bezier()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, bezier.knots.KnotCnt(), "empty spline")
+}
+
+func TestCanonicalSpline_Hermite(t *testing.T) {
+	hermBuilder := NewHermiteVertBuilder([]float64{0, 0.5, 2},
+		NewHermiteVertex(bendigo.NewVec(0, 0), bendigo.NewVec(1, 0), bendigo.NewVec(1, 0)),
+		NewHermiteVertex(bendigo.NewVec(1, 1), bendigo.NewVec(1, 2), bendigo.NewVec(1, 0)),
+		NewHermiteVertex(bendigo.NewVec(2, 2), bendigo.NewVec(1, 2), bendigo.NewVec(1, 2)),
+	)
+	canon := hermBuilder.Canonical()
+	herm, err := canon.Hermite()
+	assert.Nil(t, err)
+	bendigotest.AssertSplinesEqual(t, canon, herm.Spline(), 100)
+	for i := 0; i < 3; i++ {
+		AssertEnexVerticesAreEqual(t, hermBuilder.vertices[i], false, herm.vertices[i])
+	}
+
+	_, err = NewCanonicalSpline(nil,
+		NewCubicPolies(NewCubicPoly(0, 1, 0, 0)),
+		NewCubicPolies(NewCubicPoly(2, 0, 0, 0))).Hermite()
+	assert.NotNil(t, err, "segments are not joined")
+}
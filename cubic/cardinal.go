@@ -25,6 +25,18 @@ func (sb *CardinalVertBuilder) SetTension(tension float64) {
 	sb.CalcTangents()
 }
 
+// SetParameterization derives the knots from the vertex locations, see HermiteVertBuilder.SetParameterization
+func (sb *CardinalVertBuilder) SetParameterization(parameterization bendigo.Parameterization) {
+	sb.HermiteVertBuilder.SetParameterization(parameterization)
+	sb.CalcTangents()
+}
+
+// SetClosed adds or removes the closing segment and recalculates the tangents, see HermiteVertBuilder.SetClosed
+func (sb *CardinalVertBuilder) SetClosed(closed bool) {
+	sb.HermiteVertBuilder.SetClosed(closed)
+	sb.CalcTangents()
+}
+
 func (sb *CardinalVertBuilder) AddVertex(knotNo int, vertex bendigo.Vertex) (err error) {
 	err = sb.HermiteVertBuilder.AddVertex(knotNo, vertex)
 	if err == nil {
@@ -69,21 +81,21 @@ func (sb *CardinalVertBuilder) CalcTangents() {
 		vt.entry, vt.exit = tan, tan // TODO or clone ?
 	}
 
-	setUniformCardinalTangent(sb.vertices[0], sb.vertices[0], sb.vertices[1])
-	for i := 1; i < n-1; i++ {
-		setUniformCardinalTangent(sb.vertices[i], sb.vertices[i-1], sb.vertices[i+1]) // use vertex before and after
+	for i := 0; i < n; i++ {
+		vprev, vnext := sb.neighborVertices(i)
+		setUniformCardinalTangent(sb.vertices[i], vprev, vnext) // use vertex before and after
 	}
-	setUniformCardinalTangent(sb.vertices[n-1], sb.vertices[n-2], sb.vertices[n-1])
 
 	// handle non-uniform case: double tangent, same direction but different lengths
 	if !sb.knots.IsUniform() {
-		for i := 0; i < n-1; i++ {
+		for i := 0; i < sb.knots.SegmentCnt(); i++ {
 			// modify length of uniform tangents according to segment-length
 			segmentLen, _ := sb.knots.SegmentLen(i)
 			if segmentLen != 0 {
 				scf := 1 / segmentLen
-				sb.vertices[i].exit = sb.vertices[i].exit.Scale(scf)
-				sb.vertices[i+1].entry = sb.vertices[i+1].entry.Scale(scf)
+				vstart, vend := sb.segmentVertices(i)
+				vstart.exit = vstart.exit.Scale(scf)
+				vend.entry = vend.entry.Scale(scf)
 			}
 			// TODO segmentLen == 0
 		}
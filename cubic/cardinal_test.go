@@ -2,6 +2,7 @@ package cubic
 
 import (
 	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
 	"math"
 	"math/rand"
 	"testing"
@@ -53,3 +54,40 @@ func TestCardinalSpline(t *testing.T) {
 		AssertRandSplinePointProperty(t, cardBuilder.Spline(), isOnLineSegment, "cardinal point must be on line segment between Vase points")
 	}
 }
+
+func TestCardinalVertBuilder_SetParameterization(t *testing.T) {
+	sb := createCardinalVase()
+	sb.SetParameterization(bendigo.FuncParameterization(func(locs []bendigo.Vec) []float64 {
+		return []float64{0, 2, 3}
+	}))
+	expected := NewCardinalVertBuilder([]float64{0, 2, 3}, 0,
+		NewRawHermiteVertex(bendigo.NewVec(-1, 1)),
+		NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+		NewRawHermiteVertex(bendigo.NewVec(1, 1)))
+	bendigotest.AssertSplinesEqual(t, expected.Spline(), sb.Spline(), 30)
+}
+
+func TestCardinalVertBuilder_SetClosed(t *testing.T) {
+	for _, tknots := range [][]float64{nil, {0, 1, 3, 4}} {
+		sb := NewCardinalVertBuilder(tknots, 0.2,
+			NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+			NewRawHermiteVertex(bendigo.NewVec(2, 0)),
+			NewRawHermiteVertex(bendigo.NewVec(2, 1)),
+			NewRawHermiteVertex(bendigo.NewVec(0, 2)),
+		)
+		sb.SetClosed(true)
+		spline := sb.Canonical()
+		tstart, tend := spline.Knots().Tstart(), spline.Knots().Tend()
+		AssertSplineAt(t, spline, tend, bendigo.NewVec(0, 0))
+
+		// tangent at seam uses wrapped neighbors, same direction on both sides
+		dir := bendigo.NewVec(2, 0).Sub(bendigo.NewVec(0, 2)).Scale(0.4)
+		if tknots == nil {
+			bendigotest.AssertVecInDelta(t, dir, spline.Deriv(tstart, 1), "exit tangent at seam")
+			bendigotest.AssertVecInDelta(t, dir, spline.Deriv(tend, 1), "entry tangent at seam")
+		} else {
+			closingLen, _ := sb.Knots().SegmentLen(3)
+			bendigotest.AssertVecInDelta(t, dir.Scale(1/closingLen), spline.Deriv(tend, 1), "entry tangent at seam")
+		}
+	}
+}
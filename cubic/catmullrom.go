@@ -0,0 +1,133 @@
+package cubic
+
+import (
+	"github.com/walpod/bendigo"
+	"math"
+)
+
+// parameterization exponents of AlphaCatmullRomVertBuilder
+const (
+	UniformAlpha     = 0.
+	CentripetalAlpha = 0.5 // no cusps or self-intersections within segments
+	ChordalAlpha     = 1.
+)
+
+// AlphaCatmullRomVertBuilder is an hermite vertex-based builder for Catmull-Rom splines whose knots are derived
+// from the distances of the vertices: t[i+1] - t[i] = |loc[i+1] - loc[i]|^alpha, i.e. by an AlphaParameterization.
+// Knots and tangents are recalculated whenever vertices are added, updated or deleted
+type AlphaCatmullRomVertBuilder struct {
+	HermiteVertBuilder
+}
+
+func NewAlphaCatmullRomVertBuilder(alpha float64, vertices ...*EnexVertex) *AlphaCatmullRomVertBuilder {
+	sb := &AlphaCatmullRomVertBuilder{
+		HermiteVertBuilder: *NewHermiteVertBuilder(nil, vertices...)}
+	sb.SetAlpha(alpha)
+	return sb
+}
+
+// NewCentripetalCatmullRomVertBuilder creates a special alpha Catmull-Rom builder with alpha = 0.5
+func NewCentripetalCatmullRomVertBuilder(vertices ...*EnexVertex) *AlphaCatmullRomVertBuilder {
+	return NewAlphaCatmullRomVertBuilder(CentripetalAlpha, vertices...)
+}
+
+// NewChordalCatmullRomVertBuilder creates a special alpha Catmull-Rom builder with alpha = 1
+func NewChordalCatmullRomVertBuilder(vertices ...*EnexVertex) *AlphaCatmullRomVertBuilder {
+	return NewAlphaCatmullRomVertBuilder(ChordalAlpha, vertices...)
+}
+
+// Alpha returns the exponent of the parameterization, NaN if another parameterization has been set
+func (sb *AlphaCatmullRomVertBuilder) Alpha() float64 {
+	if ap, ok := sb.parameterization.(bendigo.AlphaParameterization); ok {
+		return ap.Alpha
+	}
+	return math.NaN()
+}
+
+func (sb *AlphaCatmullRomVertBuilder) SetAlpha(alpha float64) {
+	sb.SetParameterization(bendigo.AlphaParameterization{Alpha: alpha})
+}
+
+// SetParameterization replaces the alpha parameterization, the Barry-Goldman tangents are calculated for any knots
+func (sb *AlphaCatmullRomVertBuilder) SetParameterization(parameterization bendigo.Parameterization) {
+	sb.HermiteVertBuilder.SetParameterization(parameterization)
+	sb.CalcTangents()
+}
+
+// SetClosed adds or removes the closing segment and recalculates the tangents, see HermiteVertBuilder.SetClosed
+func (sb *AlphaCatmullRomVertBuilder) SetClosed(closed bool) {
+	sb.HermiteVertBuilder.SetClosed(closed)
+	sb.CalcTangents()
+}
+
+func (sb *AlphaCatmullRomVertBuilder) AddVertex(knotNo int, vertex bendigo.Vertex) (err error) {
+	err = sb.HermiteVertBuilder.AddVertex(knotNo, vertex)
+	if err == nil {
+		sb.CalcTangents()
+	}
+	return err
+}
+
+func (sb *AlphaCatmullRomVertBuilder) UpdateVertex(knotNo int, vertex bendigo.Vertex) (err error) {
+	err = sb.HermiteVertBuilder.UpdateVertex(knotNo, vertex)
+	if err == nil {
+		sb.CalcTangents()
+	}
+	return err
+}
+
+func (sb *AlphaCatmullRomVertBuilder) DeleteVertex(knotNo int) (err error) {
+	err = sb.HermiteVertBuilder.DeleteVertex(knotNo)
+	if err == nil {
+		sb.CalcTangents()
+	}
+	return err
+}
+
+// CalcTangents sets the tangents according to Barry-Goldman:
+// m[i] = (p[i]-p[i-1])/d[i-1] - (p[i+1]-p[i-1])/(d[i-1]+d[i]) + (p[i+1]-p[i])/d[i] with knot distances d.
+// The end tangents of an open spline are (p[1]-p[0])/(2*d[0]) and (p[n-1]-p[n-2])/(2*d[n-2]) like in uniform
+// Catmull-Rom, a closed spline uses the closing segment as neighbor of the first and last vertex
+func (sb *AlphaCatmullRomVertBuilder) CalcTangents() {
+	n := len(sb.vertices)
+	if n < 2 {
+		return
+	}
+	locs := closedLocs(sb.vertices, sb.closed) // location at each knot
+	tknots := make([]float64, sb.knots.KnotCnt())
+	for i := range tknots {
+		tknots[i], _ = sb.knots.Knot(i)
+	}
+	dim := locs[0].Dim()
+
+	// secant of the segment no. i, zero for vanishing segments
+	secant := func(i int) bendigo.Vec {
+		d := tknots[i+1] - tknots[i]
+		if d == 0 {
+			return bendigo.NewZeroVec(dim)
+		}
+		return locs[i+1].Sub(locs[i]).Scale(1 / d)
+	}
+	setTangent := func(vt *EnexVertex, tan bendigo.Vec) {
+		vt.entry, vt.exit = tan, tan
+	}
+
+	for i := 0; i < n; i++ {
+		if !sb.closed && i == 0 {
+			setTangent(sb.vertices[0], secant(0).Scale(0.5))
+			continue
+		} else if !sb.closed && i == n-1 {
+			setTangent(sb.vertices[n-1], secant(n-2).Scale(0.5))
+			continue
+		}
+		prev, next := (i+n-1)%n, i // segments before and after the vertex
+		dprev, dnext := tknots[prev+1]-tknots[prev], tknots[next+1]-tknots[next]
+		if dprev == 0 || dnext == 0 {
+			// coincident vertices: use the secant of the remaining segment
+			setTangent(sb.vertices[i], secant(prev).Add(secant(next)))
+			continue
+		}
+		tan := secant(prev).Add(secant(next)).Sub(locs[next+1].Sub(locs[prev]).Scale(1 / (dprev + dnext)))
+		setTangent(sb.vertices[i], tan)
+	}
+}
@@ -0,0 +1,105 @@
+package cubic
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"math"
+	"testing"
+)
+
+func createUnevenVertices() []*EnexVertex {
+	return []*EnexVertex{
+		NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+		NewRawHermiteVertex(bendigo.NewVec(0, 1)),
+		NewRawHermiteVertex(bendigo.NewVec(0.2, 1)),
+		NewRawHermiteVertex(bendigo.NewVec(3, 0)),
+		NewRawHermiteVertex(bendigo.NewVec(3, -2)),
+	}
+}
+
+// barryGoldman evaluates the Catmull-Rom segment between p1 and p2 by the pyramidal formulation of Barry and Goldman
+func barryGoldman(p [4]bendigo.Vec, tk [4]float64, t float64) bendigo.Vec {
+	lerp := func(a, b bendigo.Vec, ta, tb float64) bendigo.Vec {
+		return a.Scale((tb - t) / (tb - ta)).Add(b.Scale((t - ta) / (tb - ta)))
+	}
+	a1, a2, a3 := lerp(p[0], p[1], tk[0], tk[1]), lerp(p[1], p[2], tk[1], tk[2]), lerp(p[2], p[3], tk[2], tk[3])
+	b1, b2 := lerp(a1, a2, tk[0], tk[2]), lerp(a2, a3, tk[1], tk[3])
+	return lerp(b1, b2, tk[1], tk[2])
+}
+
+func TestAlphaCatmullRomVertBuilder_Uniform(t *testing.T) {
+	sb := NewAlphaCatmullRomVertBuilder(UniformAlpha, createUnevenVertices()...)
+	assert.Equal(t, []float64{0, 1, 2, 3, 4}, sb.Knots().External())
+	bendigotest.AssertSplinesEqual(t, NewCatmullRomVertBuilder(nil, createUnevenVertices()...).Spline(), sb.Spline(), 40)
+}
+
+func TestAlphaCatmullRomVertBuilder_BarryGoldman(t *testing.T) {
+	for _, alpha := range []float64{CentripetalAlpha, ChordalAlpha, 0.3} {
+		vertices := createUnevenVertices()
+		sb := NewAlphaCatmullRomVertBuilder(alpha, vertices...)
+		tknots := sb.Knots().External()
+		spline := sb.Spline()
+		for i, vt := range vertices {
+			AssertSplineAt(t, spline, tknots[i], vt.loc)
+		}
+
+		// inner segments equal the pyramidal formulation
+		for s := 1; s <= 2; s++ {
+			p := [4]bendigo.Vec{vertices[s-1].loc, vertices[s].loc, vertices[s+1].loc, vertices[s+2].loc}
+			tk := [4]float64{tknots[s-1], tknots[s], tknots[s+1], tknots[s+2]}
+			for j := 0; j <= 10; j++ {
+				at := tk[1] + float64(j)/10*(tk[2]-tk[1])
+				bendigotest.AssertVecInDelta(t, barryGoldman(p, tk, at), spline.At(at), "barry-goldman")
+			}
+		}
+	}
+}
+
+func TestAlphaCatmullRomVertBuilder_Vertices(t *testing.T) {
+	sb := NewCentripetalCatmullRomVertBuilder(createUnevenVertices()...)
+	assert.Equal(t, CentripetalAlpha, sb.Alpha())
+	assert.InDelta(t, 1, sb.Knots().External()[1], delta)
+
+	// moving a vertex changes the knots
+	assert.Nil(t, sb.UpdateVertex(1, NewRawHermiteVertex(bendigo.NewVec(0, 4))))
+	assert.InDelta(t, 2, sb.Knots().External()[1], delta)
+	AssertSplineAt(t, sb.Spline(), 2, bendigo.NewVec(0, 4))
+
+	assert.Nil(t, sb.AddVertex(5, NewRawHermiteVertex(bendigo.NewVec(3, 2))))
+	assert.Equal(t, 6, sb.Knots().KnotCnt())
+	assert.InDelta(t, sb.Knots().External()[4]+2, sb.Knots().Tend(), delta)
+
+	assert.Nil(t, sb.DeleteVertex(0))
+	assert.Equal(t, 0., sb.Knots().Tstart())
+	assert.Equal(t, 5, sb.Knots().KnotCnt())
+
+	sb = NewChordalCatmullRomVertBuilder(createUnevenVertices()...)
+	sb.SetAlpha(UniformAlpha)
+	assert.Equal(t, 4., sb.Knots().Tend())
+
+	// coincident vertices don't produce NaN
+	sb = NewCentripetalCatmullRomVertBuilder(
+		NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+		NewRawHermiteVertex(bendigo.NewVec(1, 0)),
+		NewRawHermiteVertex(bendigo.NewVec(1, 0)),
+		NewRawHermiteVertex(bendigo.NewVec(2, 1)))
+	for _, v := range sb.vertices {
+		assert.False(t, math.IsNaN(v.Exit()[0]) || math.IsNaN(v.Entry()[1]))
+	}
+}
+
+func TestAlphaCatmullRomVertBuilder_SetClosed(t *testing.T) {
+	sb := NewCentripetalCatmullRomVertBuilder(
+		NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+		NewRawHermiteVertex(bendigo.NewVec(4, 0)),
+		NewRawHermiteVertex(bendigo.NewVec(4, 1)),
+		NewRawHermiteVertex(bendigo.NewVec(0, 1)),
+	)
+	sb.SetClosed(true)
+	assert.InDeltaSlice(t, []float64{0, 2, 3, 5, 6}, sb.Knots().External(), delta)
+	spline := sb.Canonical()
+	AssertSplineAt(t, spline, 6, bendigo.NewVec(0, 0))
+	bendigotest.AssertVecInDelta(t, spline.Deriv(0, 1), spline.Deriv(6, 1), "smooth seam")
+	bendigotest.AssertVecInDelta(t, sb.vertices[2].Exit().Scale(-1), sb.vertices[0].Exit(), "symmetric shape")
+}
@@ -0,0 +1,21 @@
+package cubic
+
+import "github.com/walpod/bendigo"
+
+// ControlVertex is a vertex defined by its location only, e.g. a control point of a b-spline
+type ControlVertex struct {
+	loc bendigo.Vec
+}
+
+func NewControlVertex(loc bendigo.Vec) *ControlVertex {
+	return &ControlVertex{loc: loc}
+}
+
+func (cv *ControlVertex) Loc() bendigo.Vec {
+	return cv.loc
+}
+
+// WithShift creates a new ControlVertex, shifted (translated) in direction given by vector dv
+func (cv *ControlVertex) WithShift(dv bendigo.Vec) *ControlVertex {
+	return NewControlVertex(cv.loc.Add(dv))
+}
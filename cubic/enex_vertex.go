@@ -79,6 +79,17 @@ func (ev *EnexVertex) SetExit(exit bendigo.Vec) {
 	}
 }
 
+// setControlIndependently sets the entry or exit control without touching the other one, a leading vertex stops
+// leading because its controls don't mirror each other anymore
+func (ev *EnexVertex) setControlIndependently(control bendigo.Vec, isEntry bool) {
+	ev.leading = false
+	if isEntry {
+		ev.entry = control
+	} else {
+		ev.exit = control
+	}
+}
+
 func (ev *EnexVertex) ExitAsAbsolute() bendigo.Vec {
 	if ev.relative {
 		return ev.loc.Add(ev.exit)
@@ -190,3 +201,53 @@ func (ev *EnexVertex) WithControl(control bendigo.Vec, isEntry bool) *EnexVertex
 	nev.SetControl(control, isEntry)
 	return nev
 }
+
+// enexLocs returns the locations of the vertices
+func enexLocs(vertices []*EnexVertex) []bendigo.Vec {
+	locs := make([]bendigo.Vec, len(vertices))
+	for i, vt := range vertices {
+		locs[i] = vt.loc
+	}
+	return locs
+}
+
+// closingKnots returns knots with an additional closing knot for the segment from the last vertex back to the first
+// one (closed) or without it (!closed). A non-uniform closing segment gets the mean length of the other segments
+func closingKnots(knots bendigo.Knots, closed bool) bendigo.Knots {
+	if knots.IsUniform() {
+		if closed {
+			return bendigo.NewUniformKnots(knots.KnotCnt() + 1)
+		}
+		return bendigo.NewUniformKnots(knots.KnotCnt() - 1)
+	}
+
+	tknots := knots.External()
+	if !closed {
+		return bendigo.NewNonUniformKnots(tknots[:len(tknots)-1])
+	}
+	if len(tknots) == 0 {
+		return bendigo.NewNonUniformKnots([]float64{0})
+	}
+	segmentLen := 1.
+	if segmCnt := knots.SegmentCnt(); segmCnt > 0 && knots.Tend() > knots.Tstart() {
+		segmentLen = (knots.Tend() - knots.Tstart()) / float64(segmCnt)
+	}
+	return bendigo.NewNonUniformKnots(append(tknots, tknots[len(tknots)-1]+segmentLen))
+}
+
+// closedLocs returns the locations of the vertices, repeating the first one at the end if closed
+func closedLocs(vertices []*EnexVertex, closed bool) []bendigo.Vec {
+	locs := enexLocs(vertices)
+	if closed && len(locs) > 0 {
+		locs = append(locs, locs[0])
+	}
+	return locs
+}
+
+// cloneKnots returns an independent copy of the knots
+func cloneKnots(knots bendigo.Knots) bendigo.Knots {
+	if knots.IsUniform() {
+		return bendigo.NewUniformKnots(knots.KnotCnt())
+	}
+	return bendigo.NewNonUniformKnots(knots.External())
+}
@@ -15,8 +15,10 @@ func NewRawHermiteVertex(loc bendigo.Vec) *EnexVertex {
 }
 
 type HermiteVertBuilder struct {
-	knots    bendigo.Knots
-	vertices []*EnexVertex
+	knots            bendigo.Knots
+	vertices         []*EnexVertex
+	parameterization bendigo.Parameterization // nil: knots are maintained explicitly
+	closed           bool                     // additional segment from the last vertex back to the first one
 }
 
 func NewHermiteVertBuilder(tknots []float64, vertices ...*EnexVertex) *HermiteVertBuilder {
@@ -54,7 +56,79 @@ func (sb *HermiteVertBuilder) Vertex(knotNo int) bendigo.Vertex {
 	}
 }
 
+// Parameterization returns the parameterization the knots are derived from, nil if they are maintained explicitly
+func (sb *HermiteVertBuilder) Parameterization() bendigo.Parameterization {
+	return sb.parameterization
+}
+
+// SetParameterization derives the knots from the vertex locations by given parameterization, now and after each
+// AddVertex, UpdateVertex and DeleteVertex. nil keeps the current knots and maintains them explicitly afterwards.
+// SplitAt keeps the exact split knots until the next modification
+func (sb *HermiteVertBuilder) SetParameterization(parameterization bendigo.Parameterization) {
+	sb.parameterization = parameterization
+	sb.applyParameterization()
+}
+
+// applyParameterization recalculates the knots if a parameterization is set
+func (sb *HermiteVertBuilder) applyParameterization() {
+	if sb.parameterization != nil {
+		sb.knots = sb.parameterization.Knots(closedLocs(sb.vertices, sb.closed))
+	}
+}
+
+// Closed returns true if the spline continues from the last vertex back to the first one
+func (sb *HermiteVertBuilder) Closed() bool {
+	return sb.closed
+}
+
+// SetClosed adds (closed) or removes (!closed) the segment from the last vertex back to the first one together with
+// its closing knot, which is derived from the parameterization if set, else the closing segment gets the mean length
+// of the other segments (1 if uniform)
+func (sb *HermiteVertBuilder) SetClosed(closed bool) {
+	if closed == sb.closed {
+		return
+	}
+	sb.closed = closed
+	if sb.parameterization != nil && len(sb.vertices) > 0 {
+		sb.applyParameterization()
+	} else {
+		sb.knots = closingKnots(sb.knots, closed)
+	}
+}
+
+// segmentVertices returns the start and end vertex of a segment, the closing segment ends at the first vertex
+func (sb *HermiteVertBuilder) segmentVertices(segmentNo int) (vstart, vend *EnexVertex) {
+	return sb.vertices[segmentNo], sb.vertices[(segmentNo+1)%len(sb.vertices)]
+}
+
+// neighborVertices returns the vertices before and after the vertex with given no., wrapping around if closed.
+// The first and last vertex of an open spline are their own missing neighbor
+func (sb *HermiteVertBuilder) neighborVertices(knotNo int) (vprev, vnext *EnexVertex) {
+	n := len(sb.vertices)
+	prevNo, nextNo := knotNo-1, knotNo+1
+	if sb.closed {
+		prevNo, nextNo = (prevNo+n)%n, nextNo%n
+	} else {
+		if prevNo < 0 {
+			prevNo = 0
+		}
+		if nextNo > n-1 {
+			nextNo = n - 1
+		}
+	}
+	return sb.vertices[prevNo], sb.vertices[nextNo]
+}
+
 func (sb *HermiteVertBuilder) AddVertex(knotNo int, vertex bendigo.Vertex) (err error) {
+	err = sb.insertVertex(knotNo, vertex)
+	if err == nil {
+		sb.applyParameterization()
+	}
+	return err
+}
+
+// insertVertex adds the vertex and a knot without applying the parameterization
+func (sb *HermiteVertBuilder) insertVertex(knotNo int, vertex bendigo.Vertex) (err error) {
 	err = sb.knots.AddKnot(knotNo)
 	if err != nil {
 		return err
@@ -71,14 +145,18 @@ func (sb *HermiteVertBuilder) AddVertex(knotNo int, vertex bendigo.Vertex) (err
 }
 
 func (sb *HermiteVertBuilder) UpdateVertex(knotNo int, vertex bendigo.Vertex) (err error) {
-	if !sb.knots.KnotExists(knotNo) {
+	if knotNo < 0 || knotNo >= len(sb.vertices) { // the closing knot has no vertex of its own
 		return fmt.Errorf("knotNo %v does not exist", knotNo)
 	}
 	sb.vertices[knotNo] = vertex.(*EnexVertex)
+	sb.applyParameterization()
 	return nil
 }
 
 func (sb *HermiteVertBuilder) DeleteVertex(knotNo int) (err error) {
+	if knotNo < 0 || knotNo >= len(sb.vertices) {
+		return fmt.Errorf("knotNo %v does not exist", knotNo)
+	}
 	err = sb.knots.DeleteKnot(knotNo)
 	if err != nil {
 		return err
@@ -88,6 +166,7 @@ func (sb *HermiteVertBuilder) DeleteVertex(knotNo int) (err error) {
 	} else {
 		sb.vertices = append(sb.vertices[:knotNo], sb.vertices[knotNo+1:]...)
 	}
+	sb.applyParameterization()
 	return nil
 }
 
@@ -113,7 +192,7 @@ func (sb *HermiteVertBuilder) uniCanonical() *CanonicalSpline {
 
 	avs := make([]float64, 0, dim*4*segmCnt)
 	for i := 0; i < segmCnt; i++ {
-		vstart, vend := sb.vertices[i], sb.vertices[i+1]
+		vstart, vend := sb.segmentVertices(i)
 		for d := 0; d < dim; d++ {
 			avs = append(avs, vstart.loc[d], vend.loc[d], vstart.exit[d], vend.entry[d])
 		}
@@ -139,7 +218,7 @@ func (sb *HermiteVertBuilder) nonUniCanonical() *CanonicalSpline {
 	dim := sb.Dim()
 
 	for i := 0; i < segmCnt; i++ {
-		vstart, vend := sb.vertices[i], sb.vertices[i+1]
+		vstart, vend := sb.segmentVertices(i)
 		avs := make([]float64, 0, dim*4)
 		for d := 0; d < dim; d++ {
 			avs = append(avs, vstart.loc[d], vend.loc[d], vstart.exit[d], vend.entry[d])
@@ -174,37 +253,90 @@ func (sb *HermiteVertBuilder) nonUniCanonical() *CanonicalSpline {
 	return NewCanonicalSpline(sb.knots.External(), cubics...)
 }
 
+// segmentCubics calculates the cubic polynomials of a segment in power basis, depending on segment-local u
+func (sb *HermiteVertBuilder) segmentCubics(segmentNo int) CubicPolies {
+	vstart, vend := sb.segmentVertices(segmentNo)
+	sgl, _ := sb.knots.SegmentLen(segmentNo)
+	cubs := make([]CubicPoly, sb.Dim())
+	for d := range cubs {
+		p0, p1, m0, m1 := vstart.loc[d], vend.loc[d], sgl*vstart.exit[d], sgl*vend.entry[d]
+		cubs[d] = NewCubicPoly(p0, m0, 3*(p1-p0)-2*m0-m1, 2*(p0-p1)+m0+m1)
+	}
+	return NewCubicPolies(cubs...)
+}
+
+// SplitAt inserts a new vertex at parameter t without changing the shape of the spline, knotNo of the new vertex
+// is returned. For non-uniform knots the split segments get lengths according to t, so that the parameterization
+// and all tangents are retained. For uniform knots the tangents are rescaled to the shorter split segments, the
+// neighbouring vertices stop leading
+func (sb *HermiteVertBuilder) SplitAt(t float64) (knotNo int, err error) {
+	segmentNo, u, err := sb.knots.MapToSegment(t)
+	if err != nil {
+		return -1, err
+	}
+	if u == 0 {
+		return segmentNo, nil // vertex already exists
+	} else if u == 1 {
+		return (segmentNo + 1) % len(sb.vertices), nil // end of closing segment is the first vertex
+	}
+
+	segmentLen, _ := sb.knots.SegmentLen(segmentNo)
+	cubs := sb.segmentCubics(segmentNo)
+	loc, tan := cubs.At(u), cubs.Deriv(u, 1) // tan: derivative by segment-local u
+
+	var vertex *EnexVertex
+	if sb.knots.IsUniform() {
+		vstart, vend := sb.segmentVertices(segmentNo)
+		vstart.setControlIndependently(vstart.exit.Scale(u), false)
+		vend.setControlIndependently(vend.entry.Scale(1-u), true)
+		vertex = NewEnexVertexDep(loc, tan.Scale(u), tan.Scale(1-u), true, false, false)
+	} else {
+		tan = tan.Scale(1 / segmentLen)
+		vertex = NewEnexVertexDep(loc, tan, tan, true, false, false)
+	}
+
+	knotNo = segmentNo + 1
+	err = sb.insertVertex(knotNo, vertex)
+	if err != nil {
+		return -1, err
+	}
+	if !sb.knots.IsUniform() {
+		_ = sb.knots.SetSegmentLen(segmentNo, u*segmentLen)
+		_ = sb.knots.SetSegmentLen(segmentNo+1, (1-u)*segmentLen)
+	}
+	return knotNo, nil
+}
+
 func (sb *HermiteVertBuilder) Spline() bendigo.Spline {
 	return sb.Canonical()
 }
 
 func (sb *HermiteVertBuilder) Bezier() *BezierVertBuilder {
-	n := len(sb.vertices)
-	if n >= 2 {
-		if sb.knots.IsUniform() {
-			return sb.uniBezier()
-		} else {
-			panic("not yet implemented") // TODO
-		}
-	} else if n == 1 {
+	var bez *BezierVertBuilder
+	if sb.knots.SegmentCnt() >= 1 {
+		bez = sb.segmentsBezier()
+	} else if len(sb.vertices) == 1 {
 		// TODO or instead nil ? zv := bendigo.NewZeroVec(sb.Dim())
-		return NewBezierVertBuilder(sb.knots.External(),
-			NewBezierVertex(sb.vertices[0].loc, nil, nil))
+		bez = NewBezierVertBuilder(nil, NewBezierVertex(sb.vertices[0].loc, nil, nil))
 	} else {
-		return NewBezierVertBuilder(sb.knots.External())
+		bez = NewBezierVertBuilder(nil)
 	}
+	bez.knots, bez.closed = cloneKnots(sb.knots), sb.closed
+	return bez
 }
 
-func (sb *HermiteVertBuilder) uniBezier() *BezierVertBuilder {
-	// precondition: len(cubics) >= 1, bs.knots.IsUniform()
+func (sb *HermiteVertBuilder) segmentsBezier() *BezierVertBuilder {
+	// precondition: segmCnt >= 1
 	segmCnt := sb.knots.SegmentCnt()
 	dim := sb.Dim()
 
 	avs := make([]float64, 0, dim*4*segmCnt)
 	for i := 0; i < segmCnt; i++ {
-		vstart, vend := sb.vertices[i], sb.vertices[i+1]
+		vstart, vend := sb.segmentVertices(i)
+		// tangents are derivatives by t, bezier controls depend on segment-local u: scale by segment length (1 if uniform)
+		sgl, _ := sb.knots.SegmentLen(i)
 		for d := 0; d < dim; d++ {
-			avs = append(avs, vstart.loc[d], vend.loc[d], vstart.exit[d], vend.entry[d])
+			avs = append(avs, vstart.loc[d], vend.loc[d], sgl*vstart.exit[d], sgl*vend.entry[d])
 		}
 	}
 	a := mat.NewDense(dim*segmCnt, 4, avs)
@@ -219,7 +351,14 @@ func (sb *HermiteVertBuilder) uniBezier() *BezierVertBuilder {
 	var coefs mat.Dense
 	coefs.Mul(a, b)
 
-	return NewBezierVertBuilderByMatrix(sb.knots.External(), dim, coefs)
+	bez := NewBezierVertBuilderByMatrix(nil, dim, coefs)
+	if sb.closed {
+		// the last vertex coincides with the first one: the first vertex takes over its entry
+		last := len(bez.vertices) - 1
+		bez.vertices[0].entry = bez.vertices[last].entry
+		bez.vertices = bez.vertices[:last]
+	}
+	return bez
 }
 
 func (sb *HermiteVertBuilder) LinApproximate(fromSegmentNo, toSegmentNo int, consumer bendigo.LineConsumer, linaxParams *bendigo.LinaxParams) {
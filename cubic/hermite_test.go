@@ -3,6 +3,7 @@ package cubic
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
 	"math"
 	"testing"
 )
@@ -101,11 +102,42 @@ func TestHermiteSpline(t *testing.T) {
 	// uniform and regular non-uniform must match
 	herm = createHermParabola00to11(true).Spline()
 	nuherm := createHermParabola00to11(false).Spline()
-	AssertSplinesEqual(t, herm, nuherm, 100)
+	bendigotest.AssertSplinesEqual(t, herm, nuherm, 100)
 
 	herm = createDoubleHermParabola00to11to22(true).Spline()
 	nuherm = createDoubleHermParabola00to11to22(false).Spline()
-	AssertSplinesEqual(t, herm, nuherm, 100)
+	bendigotest.AssertSplinesEqual(t, herm, nuherm, 100)
+}
+
+func TestHermiteVertBuilder_Bezier(t *testing.T) {
+	herm := createDoubleHermParabola00to11to22(true)
+	bendigotest.AssertSplinesEqual(t, herm.Spline(), herm.Bezier().Spline(), 100)
+
+	// non-uniform: hermite -> bezier -> canonical must match hermite -> canonical
+	herm = createNonUniHermDiag00to11()
+	bendigotest.AssertSplinesEqual(t, herm.Spline(), herm.Bezier().Spline(), 100)
+	bendigotest.AssertSplinesEqual(t, herm.Spline(), herm.Bezier().DeCasteljauSpline(), 100)
+
+	herm = NewHermiteVertBuilder([]float64{0, 0.5, 2},
+		NewHermiteVertex(bendigo.NewVec(0, 0), bendigo.NewVec(0, 0), bendigo.NewVec(1, 0)),
+		NewHermiteVertex(bendigo.NewVec(1, 1), bendigo.NewVec(1, 2), bendigo.NewVec(1, 0)),
+		NewHermiteVertex(bendigo.NewVec(2, 2), bendigo.NewVec(1, 2), bendigo.NewVec(0, 0)),
+	)
+	bezier := herm.Bezier()
+	bendigotest.AssertSplinesEqual(t, herm.Spline(), bezier.Spline(), 100)
+	assert.Equal(t, herm.knots.External(), bezier.knots.External(), "knots must be retained")
+
+	// round trip: hermite -> bezier -> hermite
+	roundTrip := bezier.Hermite()
+	for i := 0; i < 3; i++ {
+		bendigotest.AssertVecInDelta(t, herm.vertices[i].Loc(), roundTrip.vertices[i].Loc(), "round trip location")
+		if i > 0 {
+			bendigotest.AssertVecInDelta(t, herm.vertices[i].Entry(), roundTrip.vertices[i].Entry(), "round trip entry")
+		}
+		if i < 2 {
+			bendigotest.AssertVecInDelta(t, herm.vertices[i].Exit(), roundTrip.vertices[i].Exit(), "round trip exit")
+		}
+	}
 }
 
 func TestHermiteLinaxSpline(t *testing.T) {
@@ -113,9 +145,9 @@ func TestHermiteLinaxSpline(t *testing.T) {
 	hermLinaxSpline := hermBuilder.LinaxSpline(bendigo.NewLinaxParams(0.02))
 	lines := hermLinaxSpline.Lines()
 	assert.Greater(t, len(lines), 1, "approximated with more than one line")
-	AssertVecInDelta(t, bendigo.NewVec(0, 0), lines[0].Pstart, "start point = [0,0]")
-	AssertVecInDelta(t, bendigo.NewVec(2, 2), lines[len(lines)-1].Pend, "end point = [2,2]")
-	// TODO pass with larger delta AssertSplinesEqual(t, hermBuilder.Spline(), hermLinaxSpline, 100)
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), lines[0].Pstart, "start point = [0,0]")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(2, 2), lines[len(lines)-1].Pend, "end point = [2,2]")
+	// TODO pass with larger delta bendigotest.AssertSplinesEqual(t, hermBuilder.Spline(), hermLinaxSpline, 100)
 
 	// start points of approximated lines must be on bezier curve and match bezier.At
 	hermBuilder = createHermParabola00to11(true)
@@ -146,3 +178,124 @@ func TestHermiteVertBuilder_DeleteVertex(t *testing.T) {
 	err = hermite.DeleteVertex(0)
 	assert.Equal(t, hermite.knots.KnotCnt(), 0, "knot-cnt %v wrong", hermite.knots.KnotCnt())
 }
+
+func TestHermiteArcLen(t *testing.T) {
+	herm := createNonUniHermDiag00to11().Canonical()
+	assert.InDelta(t, math.Sqrt2, bendigo.ArcLen(herm, delta), 1e-8, "arc length of diagonal")
+
+	// y = x^2 from 0 to 1 and from 1 to 2, shifted by 1
+	herm = createDoubleHermParabola00to11to22(false).Canonical()
+	parabolaLen := (2*math.Sqrt(5) + math.Asinh(2)) / 4
+	assert.InDelta(t, 2*parabolaLen, bendigo.ArcLen(herm, delta), 1e-8, "arc length of double parabola")
+
+	alsp := bendigo.NewArcLenSpline(herm, 8, delta)
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 1), alsp.At(parabolaLen), "middle point at half arc length")
+	for i := 0; i < 10; i++ {
+		s := float64(i) / 10 * alsp.Len()
+		tp, _ := alsp.ParamAt(s)
+		l, _ := bendigo.ArcLenInRange(herm, 0, tp, delta)
+		assert.InDeltaf(t, s, l, 1e-8, "arc length up to mapped parameter must be %v", s)
+	}
+}
+
+func TestHermiteVertBuilder_SplitAt(t *testing.T) {
+	hermBuilder := createDoubleHermParabola00to11to22(true)
+	before := hermBuilder.Spline()
+	knotNo, err := hermBuilder.SplitAt(1.6)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, knotNo, "new vertex is third vertex")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1.6, 1.36), hermBuilder.Vertex(2).Loc(), "new vertex on parabola")
+	AssertUniformSplitKeepsShape(t, before, hermBuilder.Spline(), 1, 0.6)
+
+	// non-uniform: parameterization is retained
+	hermBuilder = createDoubleHermParabola00to11to22(false)
+	before = hermBuilder.Spline()
+	knotNo, err = hermBuilder.SplitAt(0.25)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, knotNo, "new vertex is second vertex")
+	assert.Equal(t, []float64{0, 0.25, 1, 2}, hermBuilder.knots.External(), "knot inserted")
+	bendigotest.AssertSplinesEqual(t, before, hermBuilder.Spline(), 100)
+	knotNo, err = hermBuilder.SplitAt(1.5)
+	assert.Equal(t, []float64{0, 0.25, 1, 1.5, 2}, hermBuilder.knots.External(), "knot inserted")
+	bendigotest.AssertSplinesEqual(t, before, hermBuilder.Spline(), 100)
+}
+
+func TestHermiteVertBuilder_SplitAt_LeadingNeighbour(t *testing.T) {
+	hermBuilder := createDoubleHermParabola00to11to22(true)
+	middle := hermBuilder.vertices[1]
+	middle.SetLeading(true, true)
+	before := hermBuilder.Spline()
+	_, err := hermBuilder.SplitAt(1.6)
+	assert.Nil(t, err)
+	assert.False(t, middle.Leading(), "rescaled exit doesn't mirror the entry")
+	AssertUniformSplitKeepsShape(t, before, hermBuilder.Spline(), 1, 0.6)
+
+	// changing the entry keeps the split segments after the vertex
+	before = hermBuilder.Spline()
+	middle.SetEntry(bendigo.NewVec(2, 0))
+	bendigotest.AssertSplinesEqualInRange(t, before, hermBuilder.Spline(), 1, 3, 20)
+}
+
+func TestHermiteVertBuilder_SetParameterization(t *testing.T) {
+	sb := NewHermiteVertBuilder(nil,
+		NewHermiteVertex(bendigo.NewVec(0, 0), nil, bendigo.NewVec(1, 0)),
+		NewHermiteVertex(bendigo.NewVec(3, 4), nil, bendigo.NewVec(0, 1)),
+	)
+	assert.Nil(t, sb.Parameterization())
+	sb.SetParameterization(bendigo.ChordLengthParameterization())
+	assert.Equal(t, []float64{0, 5}, sb.Knots().External())
+
+	// knots follow the geometry
+	assert.Nil(t, sb.AddVertex(2, NewHermiteVertex(bendigo.NewVec(3, 5), nil, bendigo.NewVec(0, 1))))
+	assert.Equal(t, []float64{0, 5, 6}, sb.Knots().External())
+	assert.Nil(t, sb.UpdateVertex(0, NewHermiteVertex(bendigo.NewVec(3, 0), nil, bendigo.NewVec(1, 0))))
+	assert.Equal(t, []float64{0, 4, 5}, sb.Knots().External())
+	assert.Nil(t, sb.DeleteVertex(1))
+	assert.Equal(t, []float64{0, 5}, sb.Knots().External())
+
+	// split keeps the shape
+	before := sb.Spline()
+	_, err := sb.SplitAt(1)
+	assert.Nil(t, err)
+	bendigotest.AssertSplinesEqual(t, before, sb.Spline(), 20)
+	assert.Equal(t, []float64{0, 1, 5}, sb.Knots().External())
+
+	// back to explicit knots
+	sb.SetParameterization(nil)
+	assert.Nil(t, sb.UpdateVertex(0, NewHermiteVertex(bendigo.NewVec(0, 0), nil, bendigo.NewVec(1, 0))))
+	assert.Equal(t, []float64{0, 1, 5}, sb.Knots().External())
+
+	sb.SetParameterization(bendigo.UniformParameterization{})
+	assert.True(t, sb.Knots().IsUniform())
+}
+
+func TestHermiteVertBuilder_SetClosed(t *testing.T) {
+	for _, tknots := range [][]float64{nil, {0, 1, 2.5}} {
+		sb := NewHermiteVertBuilder(tknots,
+			NewHermiteVertex(bendigo.NewVec(0, 0), bendigo.NewVec(0, -1), bendigo.NewVec(1, -1)),
+			NewHermiteVertex(bendigo.NewVec(2, 0), bendigo.NewVec(1, 1), bendigo.NewVec(0, 1)),
+			NewHermiteVertex(bendigo.NewVec(1, 2), bendigo.NewVec(-1, 0), bendigo.NewVec(-1, -1)),
+		)
+		sb.SetClosed(true)
+		assert.Equal(t, 3, sb.Knots().SegmentCnt(), "closing segment added")
+		spline := sb.Canonical()
+		tend := spline.Knots().Tend()
+		AssertSplineAt(t, spline, tend, bendigo.NewVec(0, 0))
+		segmentLen, _ := sb.Knots().SegmentLen(2)
+		assert.InDelta(t, spline.Knots().Tstart()+segmentLen*3, tend, delta)
+		bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, -1), spline.Deriv(tend, 1), "entry tangent at seam")
+
+		// conversion to bezier and back keeps the closed shape
+		bez := sb.Bezier()
+		assert.True(t, bez.Closed())
+		assert.Len(t, bez.vertices, 3)
+		bendigotest.AssertSplinesEqual(t, spline, bez.Spline(), 100)
+		bendigotest.AssertSplinesEqual(t, spline, bez.Hermite().Spline(), 100)
+
+		lines := sb.LinaxSpline(bendigo.NewLinaxParams(0.01)).Lines()
+		bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), lines[len(lines)-1].Pend, "approximation ends at start point")
+
+		sb.SetClosed(false)
+		assert.Equal(t, 2, sb.Knots().SegmentCnt(), "closing segment removed")
+	}
+}
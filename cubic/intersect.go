@@ -0,0 +1,310 @@
+package cubic
+
+import (
+	"errors"
+	"github.com/walpod/bendigo"
+	"math"
+	"sort"
+)
+
+// maxIntersectDepth limits the number of recursive subdivisions during intersection
+const maxIntersectDepth = 60
+
+// overlapParamEps is the maximal parameter gap between overlaps to be joined
+const overlapParamEps = 1e-6
+
+// Intersection of two curves at parameter T0 of the first and T1 of the second curve. An overlap is a coincident part
+// of both curves from T0 to T0End on the first and from T1 to T1End on the second curve (T0 < T0End), starting at P.
+// For a single point of intersection T0End equals T0 and T1End equals T1
+type Intersection struct {
+	T0, T1       float64
+	P            bendigo.Vec
+	Overlap      bool
+	T0End, T1End float64
+}
+
+// pieceIntersection is an intersection of two bezier pieces in segment-local parameters, an overlap if the ranges
+// from u0 to u0end and u1 to u1end are not empty
+type pieceIntersection struct {
+	segmentNo0, segmentNo1 int
+	u0, u0end, u1, u1end   float64
+	overlap                bool
+}
+
+// bezierPiece is a part of a bezier segment, restricted to segment-local range [ustart, uend]
+type bezierPiece struct {
+	segmentNo    int
+	ustart, uend float64
+	controls     [4]bendigo.Vec
+}
+
+func (bp bezierPiece) split() (left, right bezierPiece) {
+	um := (bp.ustart + bp.uend) / 2
+	lc, rc := splitBezier(bp.controls, 0.5)
+	return bezierPiece{bp.segmentNo, bp.ustart, um, lc}, bezierPiece{bp.segmentNo, um, bp.uend, rc}
+}
+
+func (bp bezierPiece) bbox() *bendigo.BBox {
+	return bendigo.NewBBox(bp.controls[:]...)
+}
+
+// closestParam finds the local parameter u of the point closest to p and its distance
+func (bp bezierPiece) closestParam(p bendigo.Vec) (u, dist float64) {
+	cubs := bezierCubics(bp.controls[0], bp.controls[1], bp.controls[2], bp.controls[3])
+	dist = math.Inf(1)
+	for _, cu := range append([]float64{0, 1}, polyRoots(cubs.distDerivPoly(p), 0, 1)...) {
+		if cd := cubs.At(cu).Sub(p).Len(); cd < dist {
+			u, dist = cu, cd
+		}
+	}
+	return u, dist
+}
+
+// coincidentRange checks if the piece other is a part of the piece within tolerance and returns the local range of
+// that part, from the start to the end of other. The controls of both parts are compared, their curves deviate
+// at most by the maximal distance of the controls
+func (bp bezierPiece) coincidentRange(other bezierPiece, tolerance float64) (ustart, uend float64, ok bool) {
+	bb := bp.bbox()
+	if bb.Dist(other.controls[0]) > tolerance || bb.Dist(other.controls[3]) > tolerance {
+		return 0, 0, false
+	}
+	us, ds := bp.closestParam(other.controls[0])
+	ue, de := bp.closestParam(other.controls[3])
+	if ds > tolerance || de > tolerance || us == ue {
+		return 0, 0, false
+	}
+
+	lo, hi := math.Min(us, ue), math.Max(us, ue)
+	_, part := splitBezier(bp.controls, lo)
+	if lo < 1 {
+		part, _ = splitBezier(part, (hi-lo)/(1-lo))
+	}
+	for i, c := range other.controls {
+		j := i
+		if us > ue { // other runs in opposite direction
+			j = 3 - i
+		}
+		if part[j].Sub(c).Len() > tolerance {
+			return 0, 0, false
+		}
+	}
+	return bp.ustart + us*(bp.uend-bp.ustart), bp.ustart + ue*(bp.uend-bp.ustart), true
+}
+
+// segmentPiece returns the full segment as a piece
+func (sb *BezierVertBuilder) segmentPiece(segmentNo int) bezierPiece {
+	return bezierPiece{segmentNo, 0, 1, sb.segmentControls(segmentNo)}
+}
+
+// paramOf maps segment-local u to parameter t
+func (sb *BezierVertBuilder) paramOf(segmentNo int, u float64) float64 {
+	tstart, tend, _ := bendigo.SegmentTrange(sb.knots, segmentNo)
+	return tstart + u*(tend-tstart)
+}
+
+// intersectLines intersects the line segments p0-p1 and q0-q1 (2D), ok is false if they are parallel or don't intersect
+func intersectLines(p0, p1, q0, q1 bendigo.Vec) (s, r float64, ok bool) {
+	cross := func(a, b bendigo.Vec) float64 { return a[0]*b[1] - a[1]*b[0] }
+	pd, qd, pq := p1.Sub(p0), q1.Sub(q0), q0.Sub(p0)
+	denom := cross(pd, qd)
+	if denom == 0 {
+		return 0, 0, false
+	}
+	s, r = cross(pq, qd)/denom, cross(pq, pd)/denom
+	const eps = 1e-9
+	return s, r, s >= -eps && s <= 1+eps && r >= -eps && r <= 1+eps
+}
+
+// intersectPieces finds intersections of two bezier pieces by recursive subdivision. Pieces whose control polygons
+// (and therefore curves) can't overlap are discarded, coincident pieces are reported as overlap and flat pieces are
+// intersected as lines
+func intersectPieces(bp0, bp1 bezierPiece, tolerance float64, depth int, found func(pi pieceIntersection)) {
+	bb0, bb1 := bp0.bbox(), bp1.bbox()
+	if !bb0.Intersects(bb1) {
+		return
+	}
+
+	if us, ue, ok := bp0.coincidentRange(bp1, tolerance); ok {
+		found(pieceIntersection{bp0.segmentNo, bp1.segmentNo, us, ue, bp1.ustart, bp1.uend, true})
+		return
+	}
+	if us, ue, ok := bp1.coincidentRange(bp0, tolerance); ok {
+		found(pieceIntersection{bp0.segmentNo, bp1.segmentNo, bp0.ustart, bp0.uend, us, ue, true})
+		return
+	}
+
+	if depth >= maxIntersectDepth || (bendigo.IsFlatBezier(bp0.controls[:], tolerance) && bendigo.IsFlatBezier(bp1.controls[:], tolerance)) {
+		s, r, ok := intersectLines(bp0.controls[0], bp0.controls[3], bp1.controls[0], bp1.controls[3])
+		if ok {
+			s, r = math.Min(math.Max(s, 0), 1), math.Min(math.Max(r, 0), 1)
+			u0, u1 := bp0.ustart+s*(bp0.uend-bp0.ustart), bp1.ustart+r*(bp1.uend-bp1.ustart)
+			found(pieceIntersection{bp0.segmentNo, bp1.segmentNo, u0, u0, u1, u1, false})
+		}
+		return
+	}
+
+	// subdivide the larger piece
+	if bb0.Size().Len() >= bb1.Size().Len() {
+		left, right := bp0.split()
+		intersectPieces(left, bp1, tolerance, depth+1, found)
+		intersectPieces(right, bp1, tolerance, depth+1, found)
+	} else {
+		left, right := bp1.split()
+		intersectPieces(bp0, left, tolerance, depth+1, found)
+		intersectPieces(bp0, right, tolerance, depth+1, found)
+	}
+}
+
+// intersection converts the piece intersection into parameters of the splines, overlaps are oriented by T0
+func (sb *BezierVertBuilder) intersection(other *BezierVertBuilder, pi pieceIntersection) Intersection {
+	is := Intersection{T0: sb.paramOf(pi.segmentNo0, pi.u0), T1: other.paramOf(pi.segmentNo1, pi.u1), Overlap: pi.overlap,
+		T0End: sb.paramOf(pi.segmentNo0, pi.u0end), T1End: other.paramOf(pi.segmentNo1, pi.u1end)}
+	if is.T0 > is.T0End {
+		is.T0, is.T0End, is.T1, is.T1End = is.T0End, is.T0, is.T1End, is.T1
+	}
+	return is
+}
+
+// joinOverlaps sorts the overlaps by T0 and joins those that continue each other on both curves
+func joinOverlaps(overlaps []Intersection) []Intersection {
+	sort.Slice(overlaps, func(i, j int) bool { return overlaps[i].T0 < overlaps[j].T0 })
+	joined := make([]Intersection, 0, len(overlaps))
+	for _, ov := range overlaps {
+		if n := len(joined); n > 0 && ov.T0 <= joined[n-1].T0End+overlapParamEps {
+			last := &joined[n-1]
+			if ov.T0End <= last.T0End { // contained
+				continue
+			}
+			if math.Abs(ov.T1-last.T1End) <= overlapParamEps {
+				last.T0End, last.T1End = ov.T0End, ov.T1End
+				continue
+			}
+		}
+		joined = append(joined, ov)
+	}
+	return joined
+}
+
+// withinOverlap checks if the parameter t0 of the first curve lies within one of the overlaps
+func withinOverlap(overlaps []Intersection, t0 float64) bool {
+	for _, ov := range overlaps {
+		if t0 >= ov.T0-overlapParamEps && t0 <= ov.T0End+overlapParamEps {
+			return true
+		}
+	}
+	return false
+}
+
+// appendIntersection adds an intersection unless it is a duplicate (within tolerance) of an existing one
+func appendIntersection(intersections []Intersection, is Intersection, tolerance float64) []Intersection {
+	for _, other := range intersections {
+		if other.P.Sub(is.P).Len() <= tolerance {
+			return intersections
+		}
+	}
+	return append(intersections, is)
+}
+
+// Intersections finds all intersections with another bezier spline of dimension 2 using recursive subdivision.
+// Points of intersections closer than tolerance are merged. Coincident parts are reported as overlaps, if their
+// parameterizations are equal up to an affine change of parameter, i.e. always for curved parts but not for
+// straight parts with different speed. Points of intersections within an overlap are not reported
+func (sb *BezierVertBuilder) Intersections(other *BezierVertBuilder, tolerance float64) ([]Intersection, error) {
+	if (len(sb.vertices) > 0 && sb.Dim() != 2) || (len(other.vertices) > 0 && other.Dim() != 2) {
+		return nil, errors.New("intersections require dimension 2")
+	}
+
+	points, overlaps := make([]Intersection, 0), make([]Intersection, 0)
+	for s0 := 0; s0 < sb.knots.SegmentCnt(); s0++ {
+		for s1 := 0; s1 < other.knots.SegmentCnt(); s1++ {
+			intersectPieces(sb.segmentPiece(s0), other.segmentPiece(s1), tolerance, 0, func(pi pieceIntersection) {
+				if pi.overlap {
+					overlaps = append(overlaps, sb.intersection(other, pi))
+				} else {
+					p := sb.segmentCubics(pi.segmentNo0)
+					is := sb.intersection(other, pi)
+					is.P = p.At(pi.u0)
+					points = appendIntersection(points, is, tolerance)
+				}
+			})
+		}
+	}
+	return sb.withOverlaps(points, overlaps), nil
+}
+
+// withOverlaps joins the overlaps, sets their start points and appends them to the points outside of them
+func (sb *BezierVertBuilder) withOverlaps(points, overlaps []Intersection) []Intersection {
+	overlaps = joinOverlaps(overlaps)
+	intersections := make([]Intersection, 0, len(points)+len(overlaps))
+	for _, is := range points {
+		if !withinOverlap(overlaps, is.T0) {
+			intersections = append(intersections, is)
+		}
+	}
+	spline := sb.Spline()
+	for _, ov := range overlaps {
+		ov.P = spline.At(ov.T0)
+		intersections = append(intersections, ov)
+	}
+	return intersections
+}
+
+// SelfIntersections finds all points where the bezier spline of dimension 2 crosses itself, T0 < T1.
+// Points of self-intersections closer than tolerance are merged, parts running along each other are reported as
+// overlaps like in Intersections
+func (sb *BezierVertBuilder) SelfIntersections(tolerance float64) ([]Intersection, error) {
+	if len(sb.vertices) > 0 && sb.Dim() != 2 {
+		return nil, errors.New("self-intersections require dimension 2")
+	}
+
+	points, overlaps := make([]Intersection, 0), make([]Intersection, 0)
+	var shared bendigo.Vec // common point of adjacent pieces, not to be reported
+	found := func(pi pieceIntersection) {
+		if pi.overlap {
+			is := sb.intersection(sb, pi)
+			if is.T0 > math.Min(is.T1, is.T1End) { // the part with smaller parameters is the first one
+				is = sb.intersection(sb, pieceIntersection{pi.segmentNo1, pi.segmentNo0, pi.u1, pi.u1end, pi.u0, pi.u0end, true})
+			}
+			overlaps = append(overlaps, is)
+			return
+		}
+		p := sb.segmentCubics(pi.segmentNo0)
+		is := Intersection{T0: sb.paramOf(pi.segmentNo0, pi.u0), T1: sb.paramOf(pi.segmentNo1, pi.u1), P: p.At(pi.u0)}
+		if shared != nil && is.P.Sub(shared).Len() <= tolerance {
+			return
+		}
+		if is.T0 > is.T1 {
+			is.T0, is.T1 = is.T1, is.T0
+		}
+		is.T0End, is.T1End = is.T0, is.T1
+		points = appendIntersection(points, is, tolerance)
+	}
+
+	// loops within a segment: split into halves recursively and intersect them, flat pieces can't loop
+	var selfIntersect func(bp bezierPiece, depth int)
+	selfIntersect = func(bp bezierPiece, depth int) {
+		if depth >= maxIntersectDepth || bendigo.IsFlatBezier(bp.controls[:], tolerance) {
+			return
+		}
+		left, right := bp.split()
+		selfIntersect(left, depth+1)
+		selfIntersect(right, depth+1)
+		shared = left.controls[3]
+		intersectPieces(left, right, tolerance, depth+1, found)
+	}
+
+	segmentCnt := sb.knots.SegmentCnt()
+	for s0 := 0; s0 < segmentCnt; s0++ {
+		selfIntersect(sb.segmentPiece(s0), 0)
+		for s1 := s0 + 1; s1 < segmentCnt; s1++ {
+			shared = nil
+			if s1 == s0+1 {
+				shared = sb.vertices[s1].loc
+			} else if sb.closed && s0 == 0 && s1 == segmentCnt-1 {
+				shared = sb.vertices[0].loc // the closing segment ends at the first vertex
+			}
+			intersectPieces(sb.segmentPiece(s0), sb.segmentPiece(s1), tolerance, 0, found)
+		}
+	}
+	return sb.withOverlaps(points, overlaps), nil
+}
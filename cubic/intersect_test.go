@@ -0,0 +1,144 @@
+package cubic
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"testing"
+)
+
+const intersectTolerance = 1e-7
+
+// createBezierLine creates a bezier representing a straight line from start to end
+func createBezierLine(start, end bendigo.Vec) *BezierVertBuilder {
+	return NewBezierVertBuilder(nil,
+		NewBezierVertex(start, nil, start.Add(end.Sub(start).Scale(1./3))),
+		NewBezierVertex(end, start.Add(end.Sub(start).Scale(2./3)), nil))
+}
+
+// createBezierHump creates a bezier from (0,0) to (1,0) with maximum (0.5,0.75)
+func createBezierHump() *BezierVertBuilder {
+	return NewBezierVertBuilder(nil,
+		NewBezierVertex(bendigo.NewVec(0, 0), nil, bendigo.NewVec(0, 1)),
+		NewBezierVertex(bendigo.NewVec(1, 0), bendigo.NewVec(1, 1), nil))
+}
+
+func TestBezierVertBuilder_Intersections(t *testing.T) {
+	diag := createBezierDiag00to11()
+	horiz := createBezierLine(bendigo.NewVec(0, 0.5), bendigo.NewVec(1, 0.5))
+	intersections, err := diag.Intersections(horiz, intersectTolerance)
+	assert.Nil(t, err)
+	assert.Len(t, intersections, 1, "lines intersect once")
+	assert.InDelta(t, 0.5, intersections[0].T0, 1e-6, "parameter on diagonal")
+	assert.InDelta(t, 0.5, intersections[0].T1, 1e-6, "parameter on horizontal line")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0.5, 0.5), intersections[0].P, "point of intersection")
+
+	hump := createBezierHump()
+	intersections, _ = hump.Intersections(horiz, intersectTolerance)
+	assert.Len(t, intersections, 2, "hump is intersected twice")
+	for _, is := range intersections {
+		assert.InDelta(t, 0.5, is.P[1], 1e-6, "intersection on horizontal line")
+		assert.InDelta(t, 0, hump.Spline().At(is.T0).Sub(horiz.Spline().At(is.T1)).Len(), 1e-6, "same point on both curves")
+	}
+
+	// double S-slope is intersected by the anti-diagonal in its middle vertex
+	intersections, _ = createDoubleBezierS00to11to22().Intersections(createBezierLine(bendigo.NewVec(0, 2), bendigo.NewVec(2, 0)), intersectTolerance)
+	assert.Len(t, intersections, 1, "intersection in joint is found once")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 1), intersections[0].P, "intersection in middle vertex")
+
+	above := createBezierLine(bendigo.NewVec(0, 1), bendigo.NewVec(1, 1))
+	intersections, _ = hump.Intersections(above, intersectTolerance)
+	assert.Empty(t, intersections, "hump stays below")
+
+	// overlaps are reported once with their parameter ranges on both curves
+	intersections, _ = diag.Intersections(createBezierLine(bendigo.NewVec(0, 0), bendigo.NewVec(1, 1)), 1e-3)
+	assert.Len(t, intersections, 1, "straight overlap")
+	assert.True(t, intersections[0].Overlap)
+	intersections, _ = hump.Intersections(createBezierHump(), 1e-3)
+	assert.Len(t, intersections, 1, "curved overlap")
+	assert.Equal(t, Intersection{T0: 0, T1: 0, P: bendigo.NewVec(0, 0), Overlap: true, T0End: 1, T1End: 1}, intersections[0])
+}
+
+func TestBezierVertBuilder_Intersections_PartialOverlap(t *testing.T) {
+	// reversed middle part of the hump
+	hump := createBezierHump()
+	_, right := splitBezier(hump.segmentControls(0), 0.25)
+	middle, _ := splitBezier(right, 2./3)
+	reversed := NewBezierVertBuilder(nil,
+		NewBezierVertex(middle[3], nil, middle[2]),
+		NewBezierVertex(middle[0], middle[1], nil))
+	intersections, _ := hump.Intersections(reversed, intersectTolerance)
+	assert.Len(t, intersections, 1, "middle part overlaps")
+	is := intersections[0]
+	assert.True(t, is.Overlap)
+	assert.InDelta(t, 0.25, is.T0, 1e-6, "start on hump")
+	assert.InDelta(t, 0.75, is.T0End, 1e-6, "end on hump")
+	assert.InDelta(t, 1, is.T1, 1e-6, "reversed part starts at its end")
+	assert.InDelta(t, 0, is.T1End, 1e-6, "reversed part ends at its start")
+
+	// the first part of the hump continues by a line crossing the hump
+	first, _ := splitBezier(hump.segmentControls(0), 0.75)
+	end := bendigo.NewVec(1.2, -0.2)
+	second := NewBezierVertBuilder(nil,
+		NewBezierVertex(first[0], nil, first[1]),
+		NewBezierVertex(first[3], first[2], first[3].Add(end.Sub(first[3]).Scale(1./3))),
+		NewBezierVertex(end, first[3].Add(end.Sub(first[3]).Scale(2./3)), nil))
+	intersections, _ = hump.Intersections(second, intersectTolerance)
+	assert.Len(t, intersections, 2, "one crossing and one overlap")
+	for _, is := range intersections {
+		if is.Overlap {
+			assert.InDelta(t, 0, is.T0, 1e-6, "overlap starts at start")
+			assert.InDelta(t, 0.75, is.T0End, 1e-6, "overlap ends where the second curve leaves")
+			assert.InDelta(t, 0, is.T1, 1e-6)
+			assert.InDelta(t, 1, is.T1End, 1e-6)
+		} else {
+			assert.Greater(t, is.T0, 0.75, "crossing after the overlap")
+			assert.InDelta(t, 0, hump.Spline().At(is.T0).Sub(second.Spline().At(is.T1)).Len(), 1e-6, "same point on both curves")
+		}
+	}
+}
+
+func TestBezierVertBuilder_Intersections_Dimension(t *testing.T) {
+	hump := createBezierHump()
+	_, err := hump.Intersections(NewBezierVertBuilder(nil,
+		NewBezierVertex(bendigo.NewVec(0, 0, 0), nil, nil), NewBezierVertex(bendigo.NewVec(1, 1, 1), nil, nil)), intersectTolerance)
+	assert.NotNil(t, err, "only dimension 2 is supported")
+}
+
+func TestBezierVertBuilder_SelfIntersections(t *testing.T) {
+	intersections, err := createDoubleBezierS00to11to22().SelfIntersections(intersectTolerance)
+	assert.Nil(t, err)
+	assert.Empty(t, intersections, "no self-intersections")
+
+	// loop: controls cross each other, symmetric to x = 0.5
+	loop := NewBezierVertBuilder(nil,
+		NewBezierVertex(bendigo.NewVec(0, 0), nil, bendigo.NewVec(2, 1)),
+		NewBezierVertex(bendigo.NewVec(1, 0), bendigo.NewVec(-1, 1), nil))
+	intersections, _ = loop.SelfIntersections(intersectTolerance)
+	assert.Len(t, intersections, 1, "loop intersects itself once")
+	is := intersections[0]
+	assert.Less(t, is.T0, is.T1, "parameters are ordered")
+	assert.InDelta(t, 1, is.T0+is.T1, 1e-6, "symmetric parameters")
+	assert.InDelta(t, 0.5, is.P[0], 1e-6, "on axis of symmetry")
+
+	// two segments crossing each other: hump followed by a line back through it
+	crossing := createBezierHump()
+	crossing.AddVertex(2, NewBezierVertex(bendigo.NewVec(0, 0.5), bendigo.NewVec(1./3, 1./3), nil))
+	crossing.vertices[1] = NewBezierVertex(bendigo.NewVec(1, 0), bendigo.NewVec(1, 1), bendigo.NewVec(2./3, 1./6))
+	intersections, _ = crossing.SelfIntersections(intersectTolerance)
+	assert.Len(t, intersections, 1, "line crosses hump once")
+	assert.Less(t, intersections[0].T0, 1., "first parameter on hump")
+	assert.Greater(t, intersections[0].T1, 1., "second parameter on line")
+}
+
+func TestBezierVertBuilder_SelfIntersections_Overlap(t *testing.T) {
+	// hump and back along the same way
+	retrace := NewBezierVertBuilder(nil,
+		NewBezierVertex(bendigo.NewVec(0, 0), nil, bendigo.NewVec(0, 1)),
+		NewBezierVertex(bendigo.NewVec(1, 0), bendigo.NewVec(1, 1), bendigo.NewVec(1, 1)),
+		NewBezierVertex(bendigo.NewVec(0, 0), bendigo.NewVec(0, 1), nil))
+	intersections, err := retrace.SelfIntersections(intersectTolerance)
+	assert.Nil(t, err)
+	assert.Len(t, intersections, 1, "second segment runs back along the first")
+	assert.Equal(t, Intersection{T0: 0, T1: 2, P: bendigo.NewVec(0, 0), Overlap: true, T0End: 1, T1End: 1}, intersections[0])
+}
@@ -0,0 +1,204 @@
+package cubic
+
+import (
+	"github.com/walpod/bendigo"
+	"math"
+)
+
+// MonotoneMethod selects how the tangents of monotone splines are limited
+type MonotoneMethod int
+
+const (
+	FritschCarlson MonotoneMethod = iota // Bessel tangents scaled down into the monotonicity region of each segment
+	Steffen                              // tangents limited by the adjacent secants, no overshoot by construction
+)
+
+// MonotoneVertBuilder is an hermite vertex-based builder preserving the monotonicity of the vertices per dimension:
+// between two vertices each component lies within their range, there is no overshoot at local extrema
+type MonotoneVertBuilder struct {
+	HermiteVertBuilder
+	method MonotoneMethod
+}
+
+func NewMonotoneVertBuilder(tknots []float64, method MonotoneMethod, vertices ...*EnexVertex) *MonotoneVertBuilder {
+	sb := &MonotoneVertBuilder{
+		HermiteVertBuilder: *NewHermiteVertBuilder(tknots, vertices...),
+		method:             method}
+	sb.CalcTangents()
+	return sb
+}
+
+func (sb *MonotoneVertBuilder) Method() MonotoneMethod {
+	return sb.method
+}
+
+func (sb *MonotoneVertBuilder) SetMethod(method MonotoneMethod) {
+	sb.method = method
+	sb.CalcTangents()
+}
+
+// SetParameterization derives the knots from the vertex locations, see HermiteVertBuilder.SetParameterization
+func (sb *MonotoneVertBuilder) SetParameterization(parameterization bendigo.Parameterization) {
+	sb.HermiteVertBuilder.SetParameterization(parameterization)
+	sb.CalcTangents()
+}
+
+// SetClosed adds or removes the closing segment and recalculates the tangents, see HermiteVertBuilder.SetClosed
+func (sb *MonotoneVertBuilder) SetClosed(closed bool) {
+	sb.HermiteVertBuilder.SetClosed(closed)
+	sb.CalcTangents()
+}
+
+func (sb *MonotoneVertBuilder) AddVertex(knotNo int, vertex bendigo.Vertex) (err error) {
+	err = sb.HermiteVertBuilder.AddVertex(knotNo, vertex)
+	if err == nil {
+		sb.CalcTangents() // TODO recalculate only around new knot
+	}
+	return err
+}
+
+func (sb *MonotoneVertBuilder) UpdateVertex(knotNo int, vertex bendigo.Vertex) (err error) {
+	err = sb.HermiteVertBuilder.UpdateVertex(knotNo, vertex)
+	if err == nil {
+		sb.CalcTangents() // TODO recalculate only around updated knot
+	}
+	return err
+}
+
+func (sb *MonotoneVertBuilder) DeleteVertex(knotNo int) (err error) {
+	err = sb.HermiteVertBuilder.DeleteVertex(knotNo)
+	if err == nil {
+		sb.CalcTangents() // TODO recalculate only around deleted knot
+	}
+	return err
+}
+
+// CalcTangents calculates and sets the tangents of the hermite vertices for each dimension separately,
+// entry and exit tangents are equal. Segments with equal start and end component get zero tangents in that component
+func (sb *MonotoneVertBuilder) CalcTangents() {
+	n := len(sb.vertices)
+	if n < 2 {
+		return
+	}
+	dim := sb.vertices[0].loc.Dim()
+
+	h := segmentLens(sb.knots)
+
+	for i := 0; i < n; i++ {
+		sb.vertices[i].entry = bendigo.NewZeroVec(dim)
+		sb.vertices[i].exit = sb.vertices[i].entry
+	}
+
+	p := make([]float64, n)
+	for d := 0; d < dim; d++ {
+		for i := range p {
+			p[i] = sb.vertices[i].loc[d]
+		}
+		s := secants(p, h)
+
+		var tansd []float64
+		if sb.method == Steffen {
+			tansd = steffenTangents(h, s, n, sb.closed)
+		} else {
+			tansd = fritschCarlsonTangents(h, s, n, sb.closed)
+		}
+		for i := 0; i < n; i++ {
+			sb.vertices[i].entry[d] = tansd[i]
+		}
+	}
+}
+
+// adjacentSegments returns the segments before and after the vertex with given no., -1 if missing at an open end
+func adjacentSegments(knotNo, n int, closed bool) (prev, next int) {
+	prev, next = knotNo-1, knotNo
+	if closed {
+		prev = (prev + n) % n
+	} else if next == n-1 {
+		next = -1
+	}
+	return prev, next
+}
+
+// fritschCarlsonTangents calculates the tangents of n vertices from segment lengths h and secants s:
+// weighted averages of the adjacent secants (zero at local extrema), then scaled down per segment so that
+// alpha^2 + beta^2 <= 9 with alpha, beta the ratios of start and end tangent to the secant.
+// See "Monotone Piecewise Cubic Interpolation" (F. N. Fritsch, R. E. Carlson)
+func fritschCarlsonTangents(h, s []float64, n int, closed bool) []float64 {
+	m := make([]float64, n)
+	for i := 0; i < n; i++ {
+		prev, next := adjacentSegments(i, n, closed)
+		switch {
+		case prev < 0:
+			m[i] = s[next]
+		case next < 0:
+			m[i] = s[prev]
+		case s[prev]*s[next] <= 0: // local extremum or flat neighborhood
+			m[i] = 0
+		default:
+			m[i] = (h[next]*s[prev] + h[prev]*s[next]) / (h[prev] + h[next])
+		}
+	}
+
+	for k := range s {
+		end := (k + 1) % n
+		if s[k] == 0 {
+			m[k], m[end] = 0, 0
+			continue
+		}
+		alpha, beta := m[k]/s[k], m[end]/s[k]
+		if r := alpha*alpha + beta*beta; r > 9 {
+			tau := 3 / math.Sqrt(r)
+			m[k], m[end] = tau*alpha*s[k], tau*beta*s[k]
+		}
+	}
+	return m
+}
+
+// steffenTangents calculates the tangents of n vertices from segment lengths h and secants s, limiting the
+// parabolic estimate by the adjacent secants. See "A simple method for monotonic interpolation in one dimension"
+// (M. Steffen)
+func steffenTangents(h, s []float64, n int, closed bool) []float64 {
+	m := make([]float64, n)
+	for i := 0; i < n; i++ {
+		prev, next := adjacentSegments(i, n, closed)
+		switch {
+		case prev < 0:
+			m[i] = steffenEndTangent(h, s, 0, 1)
+		case next < 0:
+			m[i] = steffenEndTangent(h, s, n-2, n-3)
+		case h[prev]+h[next] == 0:
+			m[i] = 0
+		default:
+			p := (s[prev]*h[next] + s[next]*h[prev]) / (h[prev] + h[next])
+			m[i] = (sign(s[prev]) + sign(s[next])) * math.Min(math.Min(math.Abs(s[prev]), math.Abs(s[next])), math.Abs(p)/2)
+		}
+	}
+	return m
+}
+
+// steffenEndTangent calculates the tangent at an open end from the adjacent segment k and the one beyond l
+func steffenEndTangent(h, s []float64, k, l int) float64 {
+	if l < 0 || l >= len(s) || h[k]+h[l] == 0 {
+		return s[k]
+	}
+	p := s[k]*(1+h[k]/(h[k]+h[l])) - s[l]*h[k]/(h[k]+h[l])
+	switch {
+	case p*s[k] <= 0:
+		return 0
+	case math.Abs(p) > 2*math.Abs(s[k]):
+		return 2 * s[k]
+	default:
+		return p
+	}
+}
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,87 @@
+package cubic
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"testing"
+)
+
+// createStepVertices creates readings of a step with a flat start and end, a plateau and a steep rise
+func createStepVertices() []*EnexVertex {
+	return []*EnexVertex{
+		NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+		NewRawHermiteVertex(bendigo.NewVec(1, 0)),
+		NewRawHermiteVertex(bendigo.NewVec(2, 0.1)),
+		NewRawHermiteVertex(bendigo.NewVec(3, 5)),
+		NewRawHermiteVertex(bendigo.NewVec(4, 5)),
+		NewRawHermiteVertex(bendigo.NewVec(5, 5.2)),
+	}
+}
+
+// AssertMonotoneSegments checks that each component of the spline stays within the range of its segment ends
+// and changes monotonically in between
+func AssertMonotoneSegments(t *testing.T, spline bendigo.Spline, msg string) {
+	knots := spline.Knots()
+	for segmentNo := 0; segmentNo < knots.SegmentCnt(); segmentNo++ {
+		tstart, tend, _ := bendigo.SegmentTrange(knots, segmentNo)
+		start, end := spline.At(tstart), spline.At(tend)
+		prev := start
+		for i := 1; i <= 50; i++ {
+			p := spline.At(tstart + (tend-tstart)*float64(i)/50)
+			for d := range p {
+				if end[d] >= start[d] {
+					assert.GreaterOrEqual(t, p[d], prev[d]-delta, "%v: segment %v not increasing", msg, segmentNo)
+					assert.LessOrEqual(t, p[d], end[d]+delta, "%v: segment %v overshoots", msg, segmentNo)
+				} else {
+					assert.LessOrEqual(t, p[d], prev[d]+delta, "%v: segment %v not decreasing", msg, segmentNo)
+					assert.GreaterOrEqual(t, p[d], end[d]-delta, "%v: segment %v overshoots", msg, segmentNo)
+				}
+			}
+			prev = p
+		}
+	}
+}
+
+func TestMonotoneVertBuilder(t *testing.T) {
+	for _, tknots := range [][]float64{nil, {0, 0.5, 1, 1.2, 3, 4}} {
+		for _, method := range []MonotoneMethod{FritschCarlson, Steffen} {
+			sb := NewMonotoneVertBuilder(tknots, method, createStepVertices()...)
+			spline := sb.Spline()
+			for i, vt := range createStepVertices() {
+				at, _ := spline.Knots().Knot(i)
+				AssertSplineAt(t, spline, at, vt.Loc())
+			}
+			AssertMonotoneSegments(t, spline, "monotone")
+
+			// flat neighborhood: no tangent in y
+			assert.InDelta(t, 0, sb.vertices[1].Exit()[1], delta, "flat start")
+			assert.InDelta(t, 0, sb.vertices[4].Entry()[1], delta, "local extremum of secants")
+		}
+	}
+
+	// natural spline overshoots the same readings
+	natural := NewNaturalVertBuilder(nil, createStepVertices()...).Canonical()
+	assert.Less(t, natural.At(1.5)[1], 0., "natural spline undershoots")
+}
+
+func TestMonotoneVertBuilder_Tangents(t *testing.T) {
+	// uniform, strictly increasing: fritsch-carlson starts with the mean of the secants 1 and 3
+	sb := NewMonotoneVertBuilder(nil, FritschCarlson,
+		NewRawHermiteVertex(bendigo.NewVec(0)),
+		NewRawHermiteVertex(bendigo.NewVec(1)),
+		NewRawHermiteVertex(bendigo.NewVec(4)),
+	)
+	assert.InDelta(t, 2, sb.vertices[1].Exit()[0], delta)
+
+	// tangents of the shallow second segment are scaled down into its monotonicity region
+	assert.Nil(t, sb.UpdateVertex(2, NewRawHermiteVertex(bendigo.NewVec(1.1))))
+	alpha, beta := sb.vertices[1].Exit()[0]/0.1, sb.vertices[2].Entry()[0]/0.1
+	assert.LessOrEqual(t, alpha*alpha+beta*beta, 9+delta)
+
+	// steffen: secants 1 and 5, parabolic estimate 3 is limited by twice the smaller secant
+	sb.SetMethod(Steffen)
+	assert.Nil(t, sb.UpdateVertex(2, NewRawHermiteVertex(bendigo.NewVec(6))))
+	assert.InDelta(t, 2, sb.vertices[1].Exit()[0], delta)
+	assert.InDelta(t, 0, sb.vertices[0].Exit()[0], delta, "end tangent with opposite parabolic estimate")
+	assert.Equal(t, Steffen, sb.Method())
+}
@@ -2,8 +2,20 @@ package cubic
 
 import "github.com/walpod/bendigo"
 
+// EndCondition selects the boundary conditions of the equations for the tangents of natural splines
+type EndCondition int
+
+const (
+	NaturalEnd  EndCondition = iota // zero second derivative at the first and last vertex
+	ClampedEnd                      // given tangents at the first and last vertex
+	NotAKnotEnd                     // continuous third derivative at the second and second to last vertex
+	PeriodicEnd                     // equal first and second derivative at the first and last vertex
+)
+
 type NaturalVertBuilder struct {
 	HermiteVertBuilder
+	endCondition             EndCondition
+	startTangent, endTangent bendigo.Vec // tangents of ClampedEnd
 }
 
 func NewNaturalVertBuilder(tknots []float64, vertices ...*EnexVertex) *NaturalVertBuilder {
@@ -13,6 +25,42 @@ func NewNaturalVertBuilder(tknots []float64, vertices ...*EnexVertex) *NaturalVe
 	return sb
 }
 
+func (sb *NaturalVertBuilder) EndCondition() EndCondition {
+	return sb.endCondition
+}
+
+// SetEndCondition selects the boundary conditions and recalculates the tangents.
+// PeriodicEnd is meant for open curves whose last vertex coincides with the first one,
+// closed splines (see SetClosed) have no ends and ignore the end condition
+func (sb *NaturalVertBuilder) SetEndCondition(endCondition EndCondition) {
+	sb.endCondition = endCondition
+	sb.CalcTangents()
+}
+
+// SetClampedEnds selects ClampedEnd with given tangents (derivatives by t) at the first and last vertex,
+// a nil tangent results in the natural condition at that end
+func (sb *NaturalVertBuilder) SetClampedEnds(startTangent, endTangent bendigo.Vec) {
+	sb.startTangent, sb.endTangent = startTangent, endTangent
+	sb.SetEndCondition(ClampedEnd)
+}
+
+// ClampedEnds returns the tangents at the first and last vertex used by ClampedEnd
+func (sb *NaturalVertBuilder) ClampedEnds() (startTangent, endTangent bendigo.Vec) {
+	return sb.startTangent, sb.endTangent
+}
+
+// SetParameterization derives the knots from the vertex locations, see HermiteVertBuilder.SetParameterization
+func (sb *NaturalVertBuilder) SetParameterization(parameterization bendigo.Parameterization) {
+	sb.HermiteVertBuilder.SetParameterization(parameterization)
+	sb.CalcTangents()
+}
+
+// SetClosed adds or removes the closing segment and recalculates the tangents, see HermiteVertBuilder.SetClosed
+func (sb *NaturalVertBuilder) SetClosed(closed bool) {
+	sb.HermiteVertBuilder.SetClosed(closed)
+	sb.CalcTangents()
+}
+
 func (sb *NaturalVertBuilder) AddVertex(knotNo int, vertex bendigo.Vertex) (err error) {
 	err = sb.HermiteVertBuilder.AddVertex(knotNo, vertex)
 	if err == nil {
@@ -39,7 +87,14 @@ func (sb *NaturalVertBuilder) DeleteVertex(knotNo int) (err error) {
 
 // CalcTangents calculates and sets the tangent controls of the hermite vertices for natural spline
 // mathematical background can be found in "Interpolating Cubic Splines" - 9 (Gary D. Knott) and in
-// "An Introduction to Splines for use in Computer Graphics and Geometric Modeling" - 3.1 (Bartels, Beatty, Barsky)
+// "An Introduction to Splines for use in Computer Graphics and Geometric Modeling" - 3.1 (Bartels, Beatty, Barsky).
+// With segment lengths h and secants s[i] = (p[i+1] - p[i]) / h[i] the inner equations for the tangents m are
+// (uniform: h = 1)
+//
+//	h[i]*m[i-1] + 2*(h[i-1]+h[i])*m[i] + h[i-1]*m[i+1] = 3 * (h[i]*s[i-1] + h[i-1]*s[i])
+//
+// completed by the equations of the end condition. Closed splines wrap the inner equations around instead, if these
+// cyclic equations are singular the tangents fall back to the average of the adjacent secants
 func (sb *NaturalVertBuilder) CalcTangents() {
 	n := len(sb.vertices)
 	if n < 2 {
@@ -47,90 +102,76 @@ func (sb *NaturalVertBuilder) CalcTangents() {
 	}
 	dim := sb.vertices[0].loc.Dim()
 
+	h := segmentLens(sb.knots)
+	segmCnt := len(h)
+
 	// solve n linear equations of one dimension for given points and return tangents
-	var solve func(p []float64) []float64
-
-	if sb.knots.IsUniform() {
-		// uniform, solve equations for m[0] ... m[n-1] (A*m = p)
-		// 2 1			= 3 * (p1 - p0)
-		// 1 4 1		= 3 * (p2 - p0)
-		//   1 4 1		= 3 * (p3 - p1)
-		//  	...		= ...
-		//		  1 4 1 = 3 * (p(n-1) - p(n-3))
-		//			1 2 = 3 * (p(n-1) - p(n-2))
-		// first transform to upper-diagonal matrix: eliminate 1's below diagonal and convert diagonal to r[i]
-		// followed by a back-substitution to yield m[i]
-		solve = func(p []float64) []float64 {
-			r := make([]float64, n) // diagonal values
-			m := make([]float64, n) // resulting tangents
-
-			// forward elimination
-			r[0] = 2
-			m[0] = 3 * (p[1] - p[0])
-			for i := 1; i < n-1; i++ {
-				scl := 1 / r[i-1] // factor to scale line above
-				r[i] = 4 - scl
-				m[i] = 3*(p[i+1]-p[i-1]) - scl*m[i-1]
+	solve := func(p []float64, startTangent, endTangent *float64) []float64 {
+		s := secants(p, h)
+
+		if sb.closed || sb.endCondition == PeriodicEnd {
+			// cyclic equations, one per segment: closed wraps around by the closing segment, PeriodicEnd treats
+			// the last vertex as coinciding with the first one, m[n-1] = m[0]
+			k := segmCnt
+			a, b, c, d := make([]float64, k), make([]float64, k), make([]float64, k), make([]float64, k)
+			for i := 0; i < k; i++ {
+				hprev, sprev := h[(i+k-1)%k], s[(i+k-1)%k]
+				a[i], b[i], c[i] = h[i], 2*(hprev+h[i]), hprev
+				d[i] = 3 * (h[i]*sprev + hprev*s[i])
+			}
+			m, err := solveCyclicTridiagonal(a, b, c, d)
+			if err != nil {
+				// singular equations, e.g. for segments of zero length: average the adjacent secants instead
+				m = make([]float64, k)
+				for i := range m {
+					m[i] = (s[(i+k-1)%k] + s[i]) / 2
+				}
 			}
-			scl := 1 / r[n-2]
-			r[n-1] = 2 - scl
-			m[n-1] = 3*(p[n-1]-p[n-2]) - scl*m[n-2]
-
-			// backward substitution
-			m[n-1] /= r[n-1]
-			for i := n - 2; i >= 0; i-- {
-				m[i] = (m[i] - m[i+1]) / r[i]
+			if sb.closed {
+				return m
 			}
+			return append(m, m[0])
+		}
 
-			return m
+		if sb.endCondition == NotAKnotEnd && n < 4 {
+			// not enough inner knots: interpolating line (n = 2) or parabola (n = 3)
+			if n == 2 {
+				return []float64{s[0], s[0]}
+			}
+			cq := (s[1] - s[0]) / (h[0] + h[1])
+			return []float64{s[0] - cq*h[0], s[0] + cq*h[0], s[0] + cq*(h[0]+2*h[1])}
 		}
-	} else {
-		// prepare length of segments
-		t := make([]float64, n)
-		for i := 0; i < n-1; i++ {
-			//t[i] = knots[i+1] - knots[i]
-			t[i], _ = sb.knots.SegmentLen(i)
+
+		a, b, c, d := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+		for i := 1; i < n-1; i++ {
+			a[i], b[i], c[i] = h[i], 2*(h[i-1]+h[i]), h[i-1]
+			d[i] = 3 * (h[i]*s[i-1] + h[i-1]*s[i])
 		}
 
-		// non-uniform, solve equations
-		// 2	1           ...             = 3 * (p1 - p0) / t0
-		// t1	2*(t1+t0)   t0       ...    = 3 * (t0/t1 * p2 + (t1/t0 - t0/t1) * p1 - t1/t0 * p0)
-		//      t2          2*(t2+t1)   t1  = ...
-		//                  ...
-		//                  1           2   = 3 * (p(n-1) - p(n-2)) / t(n-2)
-		solve = func(p []float64) []float64 {
-			r := make([]float64, n) // diagonal values
-			m := make([]float64, n) // resulting tangents
-
-			// forward elimination
-			r[0] = 2
-			m[0] = 3 * (p[1] - p[0]) / t[0]
-			for i := 1; i < n-1; i++ {
-				scl := t[i] / r[i-1] // factor to scale line above
-				s := t[i] / t[i-1]
-				r[i] = 2 * (t[i] + t[i-1])
-				if i == 1 {
-					r[i] -= scl
-				} else {
-					r[i] -= scl * t[i-2]
-				}
-				m[i] = 3 * (p[i+1]/s + (s-1/s)*p[i] - s*p[i-1])
-				m[i] -= scl * m[i-1]
-			}
-			scl := 1 / r[n-2]
-			r[n-1] = 2 - scl
-			m[n-1] = 3 * (p[n-1] - p[n-2]) / t[n-2]
-			m[n-1] -= scl * m[n-2]
-
-			// backward substitution
-			m[n-1] /= r[n-1]
-			for i := n - 2; i >= 1; i-- {
-				m[i] = (m[i] - m[i+1]*t[i-1]) / r[i]
-			}
-			m[0] = (m[0] - m[1]) / r[0]
+		// start condition
+		switch {
+		case startTangent != nil:
+			b[0], c[0], d[0] = 1, 0, *startTangent
+		case sb.endCondition == NotAKnotEnd:
+			b[0], c[0] = h[1], h[0]+h[1]
+			d[0] = (h[1]*(3*h[0]+2*h[1])*s[0] + h[0]*h[0]*s[1]) / (h[0] + h[1])
+		default: // natural
+			b[0], c[0], d[0] = 2, 1, 3*s[0]
+		}
 
-			return m
+		// end condition
+		switch {
+		case endTangent != nil:
+			a[n-1], b[n-1], d[n-1] = 0, 1, *endTangent
+		case sb.endCondition == NotAKnotEnd:
+			hl, hp := h[n-2], h[n-3] // last and previous segment
+			a[n-1], b[n-1] = hl+hp, hp
+			d[n-1] = (hp*(3*hl+2*hp)*s[n-2] + hl*hl*s[n-3]) / (hl + hp)
+		default: // natural
+			a[n-1], b[n-1], d[n-1] = 1, 2, 3*s[n-2]
 		}
+
+		return solveTridiagonal(a, b, c, d)
 	}
 
 	// prepare empty tangents for all segments
@@ -147,8 +188,19 @@ func (sb *NaturalVertBuilder) CalcTangents() {
 			vertsd[i] = sb.vertices[i].loc[d]
 		}
 
+		// clamped tangents of this dimension
+		var startTangent, endTangent *float64
+		if sb.endCondition == ClampedEnd {
+			if sb.startTangent != nil {
+				startTangent = &sb.startTangent[d]
+			}
+			if sb.endTangent != nil {
+				endTangent = &sb.endTangent[d]
+			}
+		}
+
 		// solve linear equations to find tangents
-		tansd := solve(vertsd)
+		tansd := solve(vertsd, startTangent, endTangent)
 
 		// write intermediate result to vertices
 		for i := 0; i < n; i++ {
@@ -157,3 +209,24 @@ func (sb *NaturalVertBuilder) CalcTangents() {
 		}
 	}
 }
+
+// segmentLens returns the lengths of all segments, closed: including the closing segment
+func segmentLens(knots bendigo.Knots) []float64 {
+	h := make([]float64, knots.SegmentCnt())
+	for i := range h {
+		h[i], _ = knots.SegmentLen(i)
+	}
+	return h
+}
+
+// secants returns the slopes (p[i+1] - p[i]) / h[i] of the segments with lengths h, the closing segment ends at p[0].
+// Vanishing segments get slope zero
+func secants(p, h []float64) []float64 {
+	s := make([]float64, len(h))
+	for i := range s {
+		if h[i] != 0 {
+			s[i] = (p[(i+1)%len(p)] - p[i]) / h[i]
+		}
+	}
+	return s
+}
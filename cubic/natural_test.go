@@ -3,6 +3,7 @@ package cubic
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
 	"math/rand"
 	"testing"
 )
@@ -41,3 +42,146 @@ func TestNaturalHermiteSpline(t *testing.T) {
 		assert.True(t, v[1] >= 0 && v[1] <= 1, "natural point[1] must be in range -1..1")
 	}
 }
+
+func TestNaturalVertBuilder_SetParameterization(t *testing.T) {
+	locs := []bendigo.Vec{bendigo.NewVec(0, 0), bendigo.NewVec(1, 0), bendigo.NewVec(4, 4), bendigo.NewVec(5, 4)}
+	vertices := make([]*EnexVertex, len(locs))
+	for i, loc := range locs {
+		vertices[i] = NewRawHermiteVertex(loc)
+	}
+	sb := NewNaturalVertBuilder(nil, vertices...)
+	sb.SetParameterization(bendigo.ChordLengthParameterization())
+	expected := NewNaturalVertBuilder([]float64{0, 1, 6, 7}, vertices...)
+	bendigotest.AssertSplinesEqual(t, expected.Spline(), sb.Spline(), 30)
+
+	// tangents follow the new knots
+	assert.Nil(t, sb.UpdateVertex(3, NewRawHermiteVertex(bendigo.NewVec(4, 6))))
+	assert.Equal(t, []float64{0, 1, 6, 8}, sb.Knots().External())
+	vertices[3] = NewRawHermiteVertex(bendigo.NewVec(4, 6))
+	expected = NewNaturalVertBuilder([]float64{0, 1, 6, 8}, vertices...)
+	bendigotest.AssertSplinesEqual(t, expected.Spline(), sb.Spline(), 30)
+}
+
+// createNaturalFromFunc creates a natural builder through the points (t, f(t)) at the knots
+func createNaturalFromFunc(tknots []float64, f func(t float64) float64) *NaturalVertBuilder {
+	vertices := make([]*EnexVertex, len(tknots))
+	for i, t := range tknots {
+		vertices[i] = NewRawHermiteVertex(bendigo.NewVec(t, f(t)))
+	}
+	return NewNaturalVertBuilder(tknots, vertices...)
+}
+
+func AssertSplineMatchesFunc(t *testing.T, spline bendigo.Spline, f func(t float64) float64, msg string) {
+	tstart, tend := spline.Knots().Tstart(), spline.Knots().Tend()
+	for i := 0; i <= 40; i++ {
+		at := tstart + float64(i)/40*(tend-tstart)
+		bendigotest.AssertVecInDelta(t, bendigo.NewVec(at, f(at)), spline.At(at), msg)
+	}
+}
+
+func TestNaturalVertBuilder_EndConditions(t *testing.T) {
+	cubicFunc := func(t float64) float64 { return t*t*t - 2*t*t + t }
+	cubicDeriv := func(t float64) float64 { return 3*t*t - 4*t + 1 }
+	tknots := []float64{0, 0.5, 2, 3, 4.5}
+
+	sb := createNaturalFromFunc(tknots, cubicFunc)
+	assert.Equal(t, NaturalEnd, sb.EndCondition())
+	spline := sb.Canonical()
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), spline.Deriv(0, 2), "natural start")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), spline.Deriv(4.5, 2), "natural end")
+
+	// not-a-knot and clamped with exact tangents reproduce a cubic polynomial
+	sb.SetEndCondition(NotAKnotEnd)
+	AssertSplineMatchesFunc(t, sb.Spline(), cubicFunc, "not-a-knot")
+	sb.SetClampedEnds(bendigo.NewVec(1, cubicDeriv(0)), bendigo.NewVec(1, cubicDeriv(4.5)))
+	assert.Equal(t, ClampedEnd, sb.EndCondition())
+	AssertSplineMatchesFunc(t, sb.Spline(), cubicFunc, "clamped")
+
+	// uniform
+	sb = createNaturalFromFunc([]float64{0, 1, 2, 3}, cubicFunc)
+	sb.SetEndCondition(NotAKnotEnd)
+	uniform := NewNaturalVertBuilder(nil, sb.vertices...)
+	uniform.SetEndCondition(NotAKnotEnd)
+	bendigotest.AssertSplinesEqual(t, sb.Spline(), uniform.Spline(), 30)
+	AssertSplineMatchesFunc(t, uniform.Spline(), cubicFunc, "uniform not-a-knot")
+
+	// only one clamped end, the other one is natural
+	sb = createNaturalFromFunc(tknots, cubicFunc)
+	sb.SetClampedEnds(bendigo.NewVec(0, 2), nil)
+	start, end := sb.ClampedEnds()
+	assert.Nil(t, end)
+	bendigotest.AssertVecInDelta(t, start, sb.vertices[0].Exit(), "clamped start tangent")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), sb.Canonical().Deriv(4.5, 2), "natural end")
+
+	// not-a-knot with 3 vertices is a parabola
+	parabola := func(t float64) float64 { return 2*t*t - t + 1 }
+	sb = createNaturalFromFunc([]float64{0, 1, 3}, parabola)
+	sb.SetEndCondition(NotAKnotEnd)
+	AssertSplineMatchesFunc(t, sb.Spline(), parabola, "not-a-knot parabola")
+}
+
+func TestNaturalVertBuilder_PeriodicEnd(t *testing.T) {
+	for _, tknots := range [][]float64{nil, {0, 1, 1.5, 3, 4}} {
+		sb := NewNaturalVertBuilder(tknots,
+			NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+			NewRawHermiteVertex(bendigo.NewVec(2, 0)),
+			NewRawHermiteVertex(bendigo.NewVec(2, 1)),
+			NewRawHermiteVertex(bendigo.NewVec(0, 2)),
+			NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+		)
+		sb.SetEndCondition(PeriodicEnd)
+		spline := sb.Canonical()
+		tstart, tend := spline.Knots().Tstart(), spline.Knots().Tend()
+		for order := 1; order <= 2; order++ {
+			bendigotest.AssertVecInDelta(t, spline.Deriv(tstart, order), spline.Deriv(tend, order), "periodic derivative")
+		}
+
+		// inner knots are still C2
+		for k := 1; k < 4; k++ {
+			at, _ := spline.Knots().Knot(k)
+			left := spline.cubics[k-1].Deriv(1, 2).Scale(bendigo.DerivScale(spline.Knots(), k-1, 2))
+			bendigotest.AssertVecInDelta(t, left, spline.Deriv(at, 2), "continuous second derivative")
+		}
+	}
+
+	// closed curve of 2 segments
+	sb := NewNaturalVertBuilder(nil,
+		NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+		NewRawHermiteVertex(bendigo.NewVec(2, 0)),
+		NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+	)
+	sb.SetEndCondition(PeriodicEnd)
+	spline := sb.Canonical()
+	bendigotest.AssertVecInDelta(t, spline.Deriv(0, 2), spline.Deriv(2, 2), "periodic second derivative")
+}
+
+func TestNaturalVertBuilder_SetClosed(t *testing.T) {
+	for _, tknots := range [][]float64{nil, {0, 1, 1.5, 3}} {
+		sb := NewNaturalVertBuilder(tknots,
+			NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+			NewRawHermiteVertex(bendigo.NewVec(2, 0)),
+			NewRawHermiteVertex(bendigo.NewVec(2, 1)),
+			NewRawHermiteVertex(bendigo.NewVec(0, 2)),
+		)
+		sb.SetEndCondition(ClampedEnd) // ignored by closed splines
+		sb.SetClosed(true)
+		spline := sb.Canonical()
+		tstart, tend := spline.Knots().Tstart(), spline.Knots().Tend()
+		AssertSplineAt(t, spline, tend, bendigo.NewVec(0, 0))
+		for order := 1; order <= 2; order++ {
+			bendigotest.AssertVecInDelta(t, spline.Deriv(tstart, order), spline.Deriv(tend, order), "continuous derivative at seam")
+		}
+
+		// same as periodic spline with repeated first vertex
+		closingTknots := spline.Knots().External()
+		periodic := NewNaturalVertBuilder(closingTknots,
+			NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+			NewRawHermiteVertex(bendigo.NewVec(2, 0)),
+			NewRawHermiteVertex(bendigo.NewVec(2, 1)),
+			NewRawHermiteVertex(bendigo.NewVec(0, 2)),
+			NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+		)
+		periodic.SetEndCondition(PeriodicEnd)
+		bendigotest.AssertSplinesEqual(t, periodic.Spline(), spline, 100)
+	}
+}
@@ -0,0 +1,130 @@
+package cubic
+
+import "math"
+
+// solveQuadratic returns the real roots of a + b*u + c*u^2 = 0
+func solveQuadratic(a, b, c float64) []float64 {
+	if c == 0 {
+		if b == 0 {
+			return nil
+		}
+		return []float64{-a / b}
+	}
+	disc := b*b - 4*c*a
+	if disc < 0 {
+		return nil
+	} else if disc == 0 {
+		return []float64{-b / (2 * c)}
+	}
+	// numerically stable variant, avoids cancellation
+	q := -(b + math.Copysign(math.Sqrt(disc), b)) / 2
+	return []float64{q / c, a / q}
+}
+
+// solveCubic returns the real roots of a + b*u + c*u^2 + d*u^3 = 0, analytically using Cardano's or the
+// trigonometric method, polished by newton iterations
+func solveCubic(a, b, c, d float64) []float64 {
+	if math.Abs(d) <= 1e-12*math.Max(math.Abs(a), math.Max(math.Abs(b), math.Abs(c))) {
+		return solveQuadratic(a, b, c)
+	}
+
+	// normalize to u^3 + A*u^2 + B*u + C and substitute u = x - A/3 to yield depressed cubic
+	A, B, C := c/d, b/d, a/d
+	Q := (3*B - A*A) / 9
+	R := (9*A*B - 27*C - 2*A*A*A) / 54
+	D := Q*Q*Q + R*R
+
+	var roots []float64
+	if D > 0 {
+		sqrtD := math.Sqrt(D)
+		roots = []float64{math.Cbrt(R+sqrtD) + math.Cbrt(R-sqrtD) - A/3}
+	} else if Q == 0 {
+		roots = []float64{-A / 3} // triple root
+	} else {
+		theta := math.Acos(math.Max(-1, math.Min(1, R/math.Sqrt(-Q*Q*Q))))
+		sqrtQ := math.Sqrt(-Q)
+		roots = []float64{
+			2*sqrtQ*math.Cos(theta/3) - A/3,
+			2*sqrtQ*math.Cos((theta+2*math.Pi)/3) - A/3,
+			2*sqrtQ*math.Cos((theta+4*math.Pi)/3) - A/3,
+		}
+	}
+
+	coefs := []float64{a, b, c, d}
+	for i, r := range roots {
+		for iter := 0; iter < 3; iter++ {
+			f, df := polyAt(coefs, r), b+r*(2*c+3*d*r)
+			if df == 0 {
+				break
+			}
+			r -= f / df
+		}
+		roots[i] = r
+	}
+	return roots
+}
+
+// polyAt evaluates the polynomial sum(coefs[i] * u^i) using horner's method
+func polyAt(coefs []float64, u float64) float64 {
+	v := 0.
+	for i := len(coefs) - 1; i >= 0; i-- {
+		v = v*u + coefs[i]
+	}
+	return v
+}
+
+// polyRoots returns the real roots of polynomial sum(coefs[i] * u^i) within [ufrom, uto] in ascending order.
+// roots of the derivative split the interval into monotone pieces, which are searched by bisection
+func polyRoots(coefs []float64, ufrom, uto float64) []float64 {
+	n := len(coefs)
+	for n > 0 && coefs[n-1] == 0 {
+		n--
+	}
+	coefs = coefs[:n]
+	if n <= 1 {
+		return nil
+	} else if n == 2 {
+		r := -coefs[0] / coefs[1]
+		if r >= ufrom && r <= uto {
+			return []float64{r}
+		}
+		return nil
+	}
+
+	deriv := make([]float64, n-1)
+	for i := 1; i < n; i++ {
+		deriv[i-1] = float64(i) * coefs[i]
+	}
+	bounds := append(append([]float64{ufrom}, polyRoots(deriv, ufrom, uto)...), uto)
+
+	roots := make([]float64, 0, n-1)
+	addRoot := func(r float64) {
+		if len(roots) == 0 || r-roots[len(roots)-1] > 1e-12 {
+			roots = append(roots, r)
+		}
+	}
+	for i := 0; i < len(bounds)-1; i++ {
+		a, b := bounds[i], bounds[i+1]
+		fa, fb := polyAt(coefs, a), polyAt(coefs, b)
+		if fa == 0 {
+			addRoot(a)
+		} else if fb != 0 && (fa < 0) != (fb < 0) {
+			for iter := 0; iter < 100 && b-a > 1e-15; iter++ {
+				m := (a + b) / 2
+				fm := polyAt(coefs, m)
+				if fm == 0 {
+					a, b = m, m
+				} else if (fm < 0) == (fa < 0) {
+					a, fa = m, fm
+				} else {
+					b = m
+				}
+			}
+			addRoot((a + b) / 2)
+		}
+	}
+	if polyAt(coefs, uto) == 0 {
+		addRoot(uto)
+	}
+	return roots
+}
@@ -0,0 +1,49 @@
+package cubic
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"testing"
+)
+
+func TestSolveQuadratic(t *testing.T) {
+	assert.ElementsMatch(t, []float64{1, 2}, solveQuadratic(2, -3, 1), "(u-1)*(u-2)")
+	assert.Equal(t, []float64{1}, solveQuadratic(1, -2, 1), "double root")
+	assert.Empty(t, solveQuadratic(1, 0, 1), "no real roots")
+	assert.Equal(t, []float64{-0.5}, solveQuadratic(1, 2, 0), "linear")
+	assert.Empty(t, solveQuadratic(1, 0, 0), "constant")
+}
+
+func TestSolveCubic(t *testing.T) {
+	// (u-0.1)*(u-0.5)*(u-0.9)
+	roots := solveCubic(-0.045, 0.59, -1.5, 1)
+	sort.Float64s(roots)
+	assert.InDeltaSlice(t, []float64{0.1, 0.5, 0.9}, roots, delta, "three real roots")
+	// (u-2)*(u^2+1)
+	assert.InDeltaSlice(t, []float64{2}, solveCubic(-2, 1, -2, 1), delta, "one real root")
+	assert.InDeltaSlice(t, []float64{1, 1, 1}, solveCubic(-1, 3, -3, 1), 1e-5, "triple root")
+	assert.InDeltaSlice(t, []float64{0.5}, solveCubic(-1, 2, 0, 0), delta, "linear")
+}
+
+func TestPolyRoots(t *testing.T) {
+	// (u-0.1)*(u-0.5)*(u-0.9) = -0.045 + 0.59u - 1.5u^2 + u^3
+	roots := polyRoots([]float64{-0.045, 0.59, -1.5, 1}, 0, 1)
+	assert.InDeltaSlice(t, []float64{0.1, 0.5, 0.9}, roots, delta, "three roots")
+	roots = polyRoots([]float64{-0.045, 0.59, -1.5, 1}, 0.3, 1)
+	assert.InDeltaSlice(t, []float64{0.5, 0.9}, roots, delta, "roots within range only")
+
+	// quintic u*(u-1)*(u-0.25)*(u-0.75)*(u-0.5) with roots at both range limits
+	coefs := []float64{1}
+	for _, r := range []float64{0, 1, 0.25, 0.75, 0.5} {
+		next := make([]float64, len(coefs)+1)
+		for i, c := range coefs {
+			next[i+1] += c
+			next[i] -= r * c
+		}
+		coefs = next
+	}
+	assert.InDeltaSlice(t, []float64{0, 0.25, 0.5, 0.75, 1}, polyRoots(coefs, 0, 1), delta, "five roots")
+
+	assert.Empty(t, polyRoots([]float64{1, 0, 1}, -10, 10), "no real roots")
+	assert.InDeltaSlice(t, []float64{2}, polyRoots([]float64{-4, 2}, 0, 10), delta, "linear")
+}
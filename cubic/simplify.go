@@ -0,0 +1,281 @@
+package cubic
+
+import (
+	"github.com/walpod/bendigo"
+	"math"
+)
+
+const (
+	simplifySamples    = 8 // samples per original segment to fit a merged segment
+	simplifyIterations = 4 // parameter corrections of the samples
+)
+
+// Simplify removes vertices as long as the spline deviates by at most maxDist from its original shape and returns
+// the number of removed vertices. The two segments around a vertex are merged into one segment between the same
+// neighbor vertices, keeping the directions of their controls (G1 continuity is retained) and fitting the control
+// lengths to the original curve, the neighbors stop leading. The vertex with the smallest deviation is removed first.
+// The deviation approximates the Hausdorff distance between the merged segment and the original segments by the
+// distances of the points of their linear approximations within maxDist/10 (see LinApproximate) to the other
+// approximation. Deviations in between these points are not measured, hence the simplified spline may exceed maxDist
+// slightly. The first and last vertex of an open and the
+// first vertex of a closed spline are kept. Uniform knots remain uniform, non-uniform knots keep their values
+func (sb *BezierVertBuilder) Simplify(maxDist float64) (removedCnt int) {
+	n := len(sb.vertices)
+	segmentCnt := sb.knots.SegmentCnt()
+	if n < 3 || segmentCnt < 2 || maxDist <= 0 {
+		return 0
+	}
+	linaxDist := maxDist / 10
+
+	// original segments with their linear approximation, each current vertex refers to an original one
+	origControls := make([][4]bendigo.Vec, segmentCnt)
+	origLines := make([][]bendigo.Vec, segmentCnt)
+	origLens := make([]float64, segmentCnt)
+	for s := range origControls {
+		origControls[s] = sb.segmentControls(s)
+		origLines[s] = polyline(origControls[s], linaxDist)
+		origLens[s], _ = sb.knots.SegmentLen(s)
+	}
+	origNos := make([]int, n)
+	for i := range origNos {
+		origNos[i] = i
+	}
+
+	// candidate merges of the segments around each vertex
+	type merge struct {
+		controls  [4]bendigo.Vec
+		deviation float64
+	}
+	removable := func(knotNo int) bool {
+		if sb.closed {
+			return knotNo > 0 && len(sb.vertices) > 2
+		}
+		return knotNo > 0 && knotNo < len(sb.vertices)-1
+	}
+	evaluate := func(knotNo int) merge {
+		if !removable(knotNo) {
+			return merge{deviation: math.Inf(1)}
+		}
+		from, to := origNos[knotNo-1], segmentCnt
+		if knotNo+1 < len(origNos) {
+			to = origNos[knotNo+1]
+		}
+		var segmentLens []float64
+		if !sb.knots.IsUniform() {
+			segmentLens = origLens[from:to]
+		}
+		controls := fitMergedBezier(origControls[from:to], segmentLens)
+		var lines []bendigo.Vec
+		for s := from; s < to; s++ {
+			lines = append(lines, origLines[s]...)
+		}
+		return merge{controls: controls, deviation: hausdorffDist(polyline(controls, linaxDist), lines)}
+	}
+	merges := make([]merge, n)
+	for i := range merges {
+		merges[i] = evaluate(i)
+	}
+
+	for {
+		best := -1
+		for i, m := range merges {
+			if m.deviation <= maxDist && (best < 0 || m.deviation < merges[best].deviation) {
+				best = i
+			}
+		}
+		if best < 0 || !removable(best) {
+			return removedCnt
+		}
+
+		vstart, vend := sb.vertices[best-1], sb.vertices[(best+1)%len(sb.vertices)]
+		if err := sb.DeleteVertex(best); err != nil {
+			return removedCnt
+		}
+		vstart.setControlIndependently(merges[best].controls[1], false)
+		vend.setControlIndependently(merges[best].controls[2], true)
+		origNos = append(origNos[:best], origNos[best+1:]...)
+		merges = append(merges[:best], merges[best+1:]...)
+		removedCnt++
+
+		// the merges around the neighbors include the new segment
+		merges[best-1] = evaluate(best - 1)
+		if best < len(merges) {
+			merges[best] = evaluate(best)
+		}
+	}
+}
+
+// fitMergedBezier fits one bezier segment to consecutive segments given by controls with parameter lengths
+// segmentLens. The merged segment starts and ends at the same locations in the same directions, the lengths of its
+// inner controls minimize the squared distances to samples of the segments, whose parameters are improved by
+// Newton's method. Without segmentLens (uniform knots) the ratio of the lengths of adjacent segments is estimated by
+// the ratio of the control legs at their common vertex, which recovers segments split by SplitAt
+func fitMergedBezier(controls [][4]bendigo.Vec, segmentLens []float64) [4]bendigo.Vec {
+	first, last := controls[0], controls[len(controls)-1]
+	p3 := last[3]
+	startDir, endDir := controlDirection(first), controlDirection([4]bendigo.Vec{last[3], last[2], last[1], last[0]})
+
+	if segmentLens == nil {
+		segmentLens = make([]float64, len(controls))
+		segmentLens[0] = 1
+		for s := 1; s < len(controls); s++ {
+			entryLeg, exitLeg := controls[s-1][3].Sub(controls[s-1][2]).Len(), controls[s][1].Sub(controls[s][0]).Len()
+			segmentLens[s] = segmentLens[s-1]
+			if entryLeg > 0 && exitLeg > 0 {
+				segmentLens[s] *= exitLeg / entryLeg
+			}
+		}
+	}
+
+	// samples with initial parameters
+	var total float64
+	for _, l := range segmentLens {
+		total += l
+	}
+	var points []bendigo.Vec
+	var us []float64
+	tstart := 0.
+	for s, c := range controls {
+		for j := 0; j < simplifySamples; j++ {
+			u := float64(j) / simplifySamples
+			points = append(points, bendigo.DeCasteljau(c[:], u))
+			us = append(us, (tstart+u*segmentLens[s])/total)
+		}
+		tstart += segmentLens[s]
+	}
+	points = append(points, p3)
+	us = append(us, 1)
+
+	merged := FitBezierLengths(points, us, startDir, endDir)
+	for k := 0; k < simplifyIterations; k++ {
+		cubs := bezierCubics(merged[0], merged[1], merged[2], merged[3])
+		for i := 1; i < len(us)-1; i++ {
+			us[i] = NewtonParam(&cubs, points[i], us[i], 0, 1)
+		}
+		merged = FitBezierLengths(points, us, startDir, endDir)
+	}
+	return merged
+}
+
+// FitBezierLengths determines the bezier controls through the first and last point with given tangent directions
+// (unit vectors pointing into the segment), whose lengths minimize the squared distances of the points at parameters
+// us. Degenerate or reversed tangents fall back to a third of the chord
+func FitBezierLengths(points []bendigo.Vec, us []float64, startTan, endTan bendigo.Vec) [4]bendigo.Vec {
+	p0, p3 := points[0], points[len(points)-1]
+	var c00, c01, c11, x0, x1 float64
+	for i, u := range us {
+		v := 1 - u
+		b0, b1, b2, b3 := v*v*v, 3*u*v*v, 3*u*u*v, u*u*u
+		a0, a1 := startTan.Scale(b1), endTan.Scale(b2)
+		c00 += a0.Dot(a0)
+		c01 += a0.Dot(a1)
+		c11 += a1.Dot(a1)
+		rest := points[i].Sub(p0.Scale(b0 + b1)).Sub(p3.Scale(b2 + b3))
+		x0 += a0.Dot(rest)
+		x1 += a1.Dot(rest)
+	}
+
+	// solve 2x2 system by Cramer's rule
+	chord := p3.Sub(p0).Len()
+	alpha0, alpha1 := chord/3, chord/3
+	if det := c00*c11 - c01*c01; det != 0 {
+		a0, a1 := (x0*c11-x1*c01)/det, (c00*x1-c01*x0)/det
+		if eps := 1e-6 * chord; a0 >= eps && a1 >= eps {
+			alpha0, alpha1 = a0, a1
+		}
+	}
+	return [4]bendigo.Vec{p0, p0.Add(startTan.Scale(alpha0)), p3.Add(endTan.Scale(alpha1)), p3}
+}
+
+// Curve is evaluated with derivatives, e.g. a canonical spline or the cubic polynomials of a segment
+type Curve interface {
+	At(t float64) bendigo.Vec
+	Deriv(t float64, order int) bendigo.Vec
+}
+
+// NewtonParam moves the parameter t of point p towards the closest point on the curve by a Newton step on
+// (C(t) - p) * C'(t) = 0 and clamps the result to [tstart,tend]
+func NewtonParam(curve Curve, p bendigo.Vec, t, tstart, tend float64) float64 {
+	diff := curve.At(t).Sub(p)
+	d1, d2 := curve.Deriv(t, 1), curve.Deriv(t, 2)
+	denominator := d1.Dot(d1) + diff.Dot(d2)
+	if denominator == 0 {
+		return t
+	}
+	return math.Max(tstart, math.Min(tend, t-diff.Dot(d1)/denominator))
+}
+
+// controlDirection returns the unit direction from the start control to the first different control, the zero vector
+// if all controls coincide
+func controlDirection(controls [4]bendigo.Vec) bendigo.Vec {
+	for _, c := range controls[1:] {
+		if dir := c.Sub(controls[0]); dir.Len() > 0 {
+			return dir.Scale(1 / dir.Len())
+		}
+	}
+	return bendigo.NewZeroVec(controls[0].Dim())
+}
+
+// polyline returns the points of the linear approximation of the bezier controls within maxDist
+func polyline(controls [4]bendigo.Vec, maxDist float64) []bendigo.Vec {
+	points := []bendigo.Vec{controls[0]}
+	subdivideBezier(controls, 0, 1, maxDist, func(ts, te float64, pstart, pend bendigo.Vec) {
+		points = append(points, pend)
+	})
+	return points
+}
+
+// hausdorffDist approximates the Hausdorff distance of two polylines by the distances of their points to the other
+// polyline, distances between the points are not measured
+func hausdorffDist(points0, points1 []bendigo.Vec) float64 {
+	return math.Max(directedDist(points0, points1), directedDist(points1, points0))
+}
+
+// directedDist returns the max. distance of the points to the polyline of others. Both polylines run in the same
+// direction, hence the closest line is searched locally around the previous one, a local minimum may overestimate
+// the distance of a point
+func directedDist(points, others []bendigo.Vec) float64 {
+	dist := func(p bendigo.Vec, no int) float64 {
+		if no == 0 {
+			return p.Sub(others[0]).Len()
+		}
+		return lineDist(p, others[no-1], others[no])
+	}
+
+	maxDist, prev := 0., 0
+	for _, p := range points {
+		minNo, minDist := prev, dist(p, prev)
+		for no := prev + 1; no < len(others); no++ {
+			d := dist(p, no)
+			if d > minDist {
+				break
+			}
+			minNo, minDist = no, d
+		}
+		if minNo == prev {
+			for no := prev - 1; no >= 0; no-- {
+				d := dist(p, no)
+				if d > minDist {
+					break
+				}
+				minNo, minDist = no, d
+			}
+		}
+		prev = minNo
+		if minDist > maxDist {
+			maxDist = minDist
+		}
+	}
+	return maxDist
+}
+
+// lineDist returns the distance of p to the line segment from a to b
+func lineDist(p, a, b bendigo.Vec) float64 {
+	ab, ap := b.Sub(a), p.Sub(a)
+	l2 := ab.Dot(ab)
+	if l2 == 0 {
+		return ap.Len()
+	}
+	s := math.Max(0, math.Min(1, ap.Dot(ab)/l2))
+	return ap.Sub(ab.Scale(s)).Len()
+}
@@ -0,0 +1,116 @@
+package cubic
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"math"
+	"testing"
+)
+
+// AssertBezierVerticesInDelta checks that both builders have vertices with the same controls
+func AssertBezierVerticesInDelta(t *testing.T, expected, actual *BezierVertBuilder, maxDist float64) {
+	assert.Equal(t, len(expected.vertices), len(actual.vertices), "number of vertices")
+	for i := 0; i < len(expected.vertices) && i < len(actual.vertices); i++ {
+		ev, av := expected.BezierVertex(i), actual.BezierVertex(i)
+		assert.InDelta(t, 0, ev.Loc().Sub(av.Loc()).Len(), maxDist, "loc of vertex %v", i)
+		assert.InDelta(t, 0, ev.Entry().Sub(av.Entry()).Len(), maxDist, "entry of vertex %v", i)
+		assert.InDelta(t, 0, ev.Exit().Sub(av.Exit()).Len(), maxDist, "exit of vertex %v", i)
+	}
+}
+
+// createNaturalCircle approximates the unit circle by a closed natural spline through cnt points
+func createNaturalCircle(cnt int) *NaturalVertBuilder {
+	vertices := make([]*EnexVertex, cnt)
+	for i := range vertices {
+		phi := 2 * math.Pi * float64(i) / float64(cnt)
+		vertices[i] = NewRawHermiteVertex(bendigo.NewVec(math.Cos(phi), math.Sin(phi)))
+	}
+	sb := NewNaturalVertBuilder(nil, vertices...)
+	sb.SetClosed(true)
+	return sb
+}
+
+func TestBezierVertBuilder_Simplify_SplitAt(t *testing.T) {
+	for _, create := range []func() *BezierVertBuilder{createDoubleBezierS00to11to22, createNonUniDoubleBezierS00to11to22} {
+		sb := create()
+		tend := sb.Knots().Tend()
+		for _, u := range []float64{0.9, 0.65, 0.6, 0.3, 0.2, 0.1} { // descending, uniform knots are appended
+			_, err := sb.SplitAt(u * tend)
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, 9, sb.Knots().KnotCnt())
+
+		// split vertices are removed again, the vertex between the two S curves is kept
+		assert.Equal(t, 6, sb.Simplify(1e-6))
+		AssertBezierVerticesInDelta(t, create(), sb, 1e-6)
+		assert.Equal(t, create().Knots().External(), sb.Knots().External())
+	}
+}
+
+func TestBezierVertBuilder_Simplify_Tolerance(t *testing.T) {
+	herm := NewNaturalVertBuilder(nil)
+	for i := 0; i <= 200; i++ {
+		x := 4 * math.Pi * float64(i) / 200
+		_ = herm.AddVertex(i, NewRawHermiteVertex(bendigo.NewVec(x, math.Sin(x))))
+	}
+	original := herm.Bezier()
+
+	for _, maxDist := range []float64{1e-2, 1e-4} {
+		sb := herm.Bezier()
+		removedCnt := sb.Simplify(maxDist)
+		assert.Equal(t, 201-removedCnt, sb.Knots().KnotCnt())
+		assert.Less(t, sb.Knots().KnotCnt(), 50, "compact for maxDist = %v", maxDist)
+		bendigotest.AssertVecInDelta(t, original.BezierVertex(0).Loc(), sb.BezierVertex(0).Loc(), "first vertex kept")
+		bendigotest.AssertVecInDelta(t, original.BezierVertex(200).Loc(), sb.BezierVertex(sb.Knots().KnotCnt()-1).Loc(), "last vertex kept")
+
+		// sampled original curve is close to the simplified one
+		spline, simplified := original.Spline(), sb.Canonical()
+		for i := 0; i <= 1000; i++ {
+			_, _, dist, err := simplified.ClosestPoint(spline.At(200 * float64(i) / 1000))
+			assert.Nil(t, err)
+			assert.LessOrEqual(t, dist, 1.2*maxDist)
+		}
+	}
+
+	// nothing to remove for zero tolerance
+	sb := herm.Bezier()
+	assert.Equal(t, 0, sb.Simplify(0))
+	assert.Equal(t, 201, sb.Knots().KnotCnt())
+}
+
+func TestBezierVertBuilder_Simplify_Closed(t *testing.T) {
+	sb := createNaturalCircle(64).Bezier()
+	first := sb.BezierVertex(0)
+	sb.Simplify(1e-4)
+	assert.True(t, sb.Closed())
+	assert.Less(t, sb.Knots().KnotCnt(), 16)
+	assert.Equal(t, sb.Knots().KnotCnt()-1, sb.Knots().SegmentCnt(), "closing segment")
+	assert.Same(t, first, sb.BezierVertex(0), "first vertex kept")
+	spline := sb.Canonical()
+	for i := 0; i < 100; i++ {
+		at := spline.Knots().Tend() * float64(i) / 100
+		assert.InDelta(t, 1, spline.At(at).Len(), 2e-4, "on circle at t = %v", at)
+	}
+
+	// huge tolerance keeps two vertices
+	sb.Simplify(10)
+	assert.Equal(t, 3, sb.Knots().KnotCnt())
+	assert.Equal(t, 2, sb.Knots().SegmentCnt())
+}
+
+func TestBezierVertBuilder_Simplify_Leading(t *testing.T) {
+	// straight line with leading vertices
+	sb := NewBezierVertBuilder(nil,
+		NewBezierVertex(bendigo.NewVec(0, 0), nil, bendigo.NewVec(1, 0)),
+		NewBezierVertex(bendigo.NewVec(3, 0), nil, bendigo.NewVec(4, 0)),
+		NewBezierVertex(bendigo.NewVec(6, 0), bendigo.NewVec(5, 0), nil),
+	)
+	first, last := sb.BezierVertex(0), sb.BezierVertex(2)
+	firstEntry, lastExit := first.Entry(), last.Exit()
+	assert.Equal(t, 1, sb.Simplify(1e-6))
+	assert.False(t, first.Leading(), "merged exit doesn't mirror the entry")
+	assert.False(t, last.Leading(), "merged entry doesn't mirror the exit")
+	bendigotest.AssertVecInDelta(t, firstEntry, first.Entry(), "entry of first vertex unchanged")
+	bendigotest.AssertVecInDelta(t, lastExit, last.Exit(), "exit of last vertex unchanged")
+}
@@ -0,0 +1,306 @@
+package cubic
+
+import (
+	"github.com/walpod/bendigo"
+	"math"
+)
+
+// SmoothingVertBuilder builds the natural cubic spline g with knots at the parameters of the points, which minimizes
+// the sum of weights[i] * |points[i] - g(t[i])|^2 plus lambda times the integral of the squared second derivative,
+// i.e. lambda = 0 interpolates the points and lambda -> infinity approaches the weighted least-squares line.
+// The tangents are calculated by the algorithm of Reinsch, solving banded equations like NaturalVertBuilder
+type SmoothingVertBuilder struct {
+	knots   bendigo.Knots
+	points  []bendigo.Vec
+	weights []float64 // nil: all 1
+	lambda  float64
+}
+
+// NewSmoothingVertBuilder creates a smoothing spline builder for the points at strictly increasing tknots (nil: uniform),
+// weights > 0 per point (nil: all 1) and smoothing factor lambda >= 0
+func NewSmoothingVertBuilder(tknots []float64, points []bendigo.Vec, weights []float64, lambda float64) *SmoothingVertBuilder {
+	var knots bendigo.Knots
+	if tknots == nil {
+		knots = bendigo.NewUniformKnots(len(points))
+	} else {
+		if len(tknots) != len(points) {
+			panic("tknots and points must have same length")
+		}
+		for i := 1; i < len(tknots); i++ {
+			if tknots[i] <= tknots[i-1] {
+				panic("tknots must be strictly increasing")
+			}
+		}
+		knots = bendigo.NewNonUniformKnots(tknots)
+	}
+	if weights != nil && len(weights) != len(points) {
+		panic("weights and points must have same length")
+	}
+	return &SmoothingVertBuilder{knots: knots, points: points, weights: weights, lambda: lambda}
+}
+
+func (sb *SmoothingVertBuilder) Knots() bendigo.Knots {
+	return sb.knots
+}
+
+func (sb *SmoothingVertBuilder) Dim() int {
+	if len(sb.points) == 0 {
+		return 0
+	} else {
+		return sb.points[0].Dim()
+	}
+}
+
+func (sb *SmoothingVertBuilder) Points() []bendigo.Vec {
+	return sb.points
+}
+
+// Weights returns the weights of the points, nil if all are 1
+func (sb *SmoothingVertBuilder) Weights() []float64 {
+	return sb.weights
+}
+
+func (sb *SmoothingVertBuilder) Lambda() float64 {
+	return sb.lambda
+}
+
+func (sb *SmoothingVertBuilder) SetLambda(lambda float64) {
+	sb.lambda = lambda
+}
+
+// weight returns the weight of point no. i
+func (sb *SmoothingVertBuilder) weight(i int) float64 {
+	if sb.weights == nil {
+		return 1
+	}
+	return sb.weights[i]
+}
+
+// Hermite returns the smoothing spline as hermite builder with vertices at the knots
+func (sb *SmoothingVertBuilder) Hermite() *HermiteVertBuilder {
+	n := len(sb.points)
+	vertices := make([]*EnexVertex, n)
+	switch {
+	case n == 1:
+		vertices[0] = NewHermiteVertex(sb.points[0], nil, bendigo.NewZeroVec(sb.Dim()))
+	case n == 2:
+		// straight line through both points
+		h, _ := sb.knots.SegmentLen(0)
+		tangent := sb.points[1].Sub(sb.points[0]).Scale(1 / h)
+		vertices[0] = NewHermiteVertex(sb.points[0], nil, tangent)
+		vertices[1] = NewHermiteVertex(sb.points[1], nil, tangent)
+	case n >= 3:
+		sys := sb.system()
+		f := sys.factor(sb.lambda)
+		locs, tangents := sys.smooth(f, sb.lambda, sb.points)
+		for i := range vertices {
+			vertices[i] = NewHermiteVertex(locs[i], nil, tangents[i])
+		}
+	}
+
+	var tknots []float64
+	if !sb.knots.IsUniform() {
+		tknots = sb.knots.External()
+	}
+	return NewHermiteVertBuilder(tknots, vertices...)
+}
+
+func (sb *SmoothingVertBuilder) Canonical() *CanonicalSpline {
+	return sb.Hermite().Canonical()
+}
+
+func (sb *SmoothingVertBuilder) Spline() bendigo.Spline {
+	return sb.Canonical()
+}
+
+func (sb *SmoothingVertBuilder) LinApproximate(fromSegmentNo, toSegmentNo int, consumer bendigo.LineConsumer, linaxParams *bendigo.LinaxParams) {
+	sb.Hermite().LinApproximate(fromSegmentNo, toSegmentNo, consumer, linaxParams)
+}
+
+func (sb *SmoothingVertBuilder) LinaxSpline(linaxParams *bendigo.LinaxParams) *bendigo.LinaxSpline {
+	return bendigo.BuildLinaxSpline(sb, linaxParams)
+}
+
+// GCV returns the generalized cross-validation score of the smoothing spline with given lambda,
+// (1/n) * sum of weights[i] * |points[i] - g(t[i])|^2 / (1 - tr(S)/n)^2, where S maps the points to the smoothed locations. It estimates the mean squared prediction error,
+// +Inf for less than 3 points or lambda = 0
+func (sb *SmoothingVertBuilder) GCV(lambda float64) float64 {
+	n := len(sb.points)
+	if n < 3 {
+		return math.Inf(1)
+	}
+	sys := sb.system()
+	f := sys.factor(lambda)
+	locs, _ := sys.smooth(f, lambda, sb.points)
+	rss := 0.
+	for i, loc := range locs {
+		dist := sb.points[i].Sub(loc).Len()
+		rss += sb.weight(i) * dist * dist
+	}
+	denom := 1 - sys.traceSmoother(f, lambda)/float64(n)
+	if denom <= 0 {
+		return math.Inf(1)
+	}
+	return rss / float64(n) / (denom * denom)
+}
+
+// GCVLambda returns the lambda with minimal GCV score, found by a scan over 12 decades of lambda around the balance
+// of both terms followed by a golden-section search, 0 for less than 3 points. Use SetLambda to apply it
+func (sb *SmoothingVertBuilder) GCVLambda() float64 {
+	if len(sb.points) < 3 {
+		return 0
+	}
+
+	// lambda where the roughness and the data term of the equations have the same magnitude
+	sys := sb.system()
+	var traceR, traceB float64
+	for j := range sys.r0 {
+		traceR += sys.r0[j]
+		traceB += sys.b0[j]
+	}
+	scale := math.Log10(traceR / traceB)
+	score := func(x float64) float64 {
+		return sb.GCV(math.Pow(10, x))
+	}
+
+	// coarse scan
+	const from, to, step = -6., 6., 0.25
+	best, bestScore := from, score(scale+from)
+	for x := from + step; x <= to; x += step {
+		if s := score(scale + x); s < bestScore {
+			best, bestScore = x, s
+		}
+	}
+
+	// golden-section search between the neighbors of the best scan value
+	invPhi := (math.Sqrt(5) - 1) / 2
+	a, b := scale+best-step, scale+best+step
+	c, d := b-invPhi*(b-a), a+invPhi*(b-a)
+	sc, sd := score(c), score(d)
+	for i := 0; i < 40; i++ {
+		if sc < sd {
+			b, d, sd = d, c, sc
+			c = b - invPhi*(b-a)
+			sc = score(c)
+		} else {
+			a, c, sc = c, d, sd
+			d = a + invPhi*(b-a)
+			sd = score(d)
+		}
+	}
+	return math.Pow(10, (a+b)/2)
+}
+
+// smoothingSystem holds the band matrices of the Reinsch algorithm with one equation per inner knot:
+// R (diagonal r0, off-diagonal r1) and Q^T W^-1 Q (diagonal b0, off-diagonals b1 and b2), where Q maps locations
+// to the differences of neighbored secants, column j has entries q[j][0..2] at rows j..j+2
+type smoothingSystem struct {
+	h          []float64 // segment lengths
+	w          []float64 // weights
+	q          [][3]float64
+	r0, r1     []float64
+	b0, b1, b2 []float64
+}
+
+func (sb *SmoothingVertBuilder) system() *smoothingSystem {
+	n := len(sb.points)
+	m := n - 2
+	sys := &smoothingSystem{h: make([]float64, n-1), w: make([]float64, n), q: make([][3]float64, m),
+		r0: make([]float64, m), r1: make([]float64, m),
+		b0: make([]float64, m), b1: make([]float64, m), b2: make([]float64, m)}
+	for i := range sys.h {
+		sys.h[i], _ = sb.knots.SegmentLen(i)
+	}
+	for i := range sys.w {
+		sys.w[i] = sb.weight(i)
+	}
+	for j := 0; j < m; j++ {
+		h0, h1 := sys.h[j], sys.h[j+1]
+		sys.q[j] = [3]float64{1 / h0, -1/h0 - 1/h1, 1 / h1}
+		sys.r0[j] = (h0 + h1) / 3
+		sys.r1[j] = h1 / 6
+	}
+
+	// element (j, j+d) of Q^T W^-1 Q sums over the common rows of column j and j+d
+	band := [3][]float64{sys.b0, sys.b1, sys.b2}
+	for j := 0; j < m; j++ {
+		for d := 0; d <= 2 && j+d < m; d++ {
+			for r := j + d; r <= j+2; r++ {
+				band[d][j] += sys.q[j][r-j] * sys.q[j+d][r-j-d] / sys.w[r]
+			}
+		}
+	}
+	return sys
+}
+
+// factor factorizes R + lambda * Q^T W^-1 Q
+func (sys *smoothingSystem) factor(lambda float64) *pentadiagonalLDL {
+	m := len(sys.r0)
+	a, b, c := make([]float64, m), make([]float64, m), make([]float64, m)
+	for j := 0; j < m; j++ {
+		a[j] = sys.r0[j] + lambda*sys.b0[j]
+		b[j] = sys.r1[j] + lambda*sys.b1[j]
+		c[j] = lambda * sys.b2[j]
+	}
+	return factorPentadiagonal(a, b, c)
+}
+
+// smooth calculates the smoothed locations and their tangents (derivatives by t) for each dimension:
+// the second derivatives gamma at the inner knots solve (R + lambda * Q^T W^-1 Q) gamma = Q^T y,
+// the locations are g = y - lambda * W^-1 Q gamma
+func (sys *smoothingSystem) smooth(f *pentadiagonalLDL, lambda float64, points []bendigo.Vec) (locs, tangents []bendigo.Vec) {
+	n, m := len(points), len(sys.q)
+	dim := points[0].Dim()
+	locs, tangents = make([]bendigo.Vec, n), make([]bendigo.Vec, n)
+	for i := range locs {
+		locs[i], tangents[i] = bendigo.NewZeroVec(dim), bendigo.NewZeroVec(dim)
+	}
+
+	for d := 0; d < dim; d++ {
+		qty := make([]float64, m)
+		for j := range qty {
+			for r := 0; r <= 2; r++ {
+				qty[j] += sys.q[j][r] * points[j+r][d]
+			}
+		}
+		gamma := f.solve(qty)
+
+		// locations
+		g := make([]float64, n)
+		for i := range g {
+			qgamma := 0.
+			for j := i - 2; j <= i; j++ {
+				if j >= 0 && j < m {
+					qgamma += sys.q[j][i-j] * gamma[j]
+				}
+			}
+			g[i] = points[i][d] - lambda*qgamma/sys.w[i]
+		}
+
+		// second derivatives at all knots, natural ends
+		sigma := make([]float64, n)
+		copy(sigma[1:], gamma)
+
+		for i := 0; i < n-1; i++ {
+			h := sys.h[i]
+			tangents[i][d] = (g[i+1]-g[i])/h - h*(2*sigma[i]+sigma[i+1])/6
+		}
+		h := sys.h[n-2]
+		tangents[n-1][d] = (g[n-1]-g[n-2])/h + h*(sigma[n-2]+2*sigma[n-1])/6
+		for i := range g {
+			locs[i][d] = g[i]
+		}
+	}
+	return locs, tangents
+}
+
+// traceSmoother returns the trace of the matrix S mapping the points to the smoothed locations,
+// tr(S) = n - lambda * tr((R + lambda * Q^T W^-1 Q)^-1 Q^T W^-1 Q), which needs only the band of the inverse
+func (sys *smoothingSystem) traceSmoother(f *pentadiagonalLDL, lambda float64) float64 {
+	s0, s1, s2 := f.inverseBand()
+	tr := 0.
+	for j := range s0 {
+		tr += s0[j]*sys.b0[j] + 2*s1[j]*sys.b1[j] + 2*s2[j]*sys.b2[j]
+	}
+	return float64(len(sys.w)) - lambda*tr
+}
@@ -0,0 +1,117 @@
+package cubic
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// createNoisySine samples one period of a sine at cnt uniform knots with gaussian noise
+func createNoisySine(cnt int, noise float64) (points, exact []bendigo.Vec) {
+	rnd := rand.New(rand.NewSource(1))
+	points, exact = make([]bendigo.Vec, cnt), make([]bendigo.Vec, cnt)
+	for i := range points {
+		y := math.Sin(2 * math.Pi * float64(i) / float64(cnt-1))
+		exact[i] = bendigo.NewVec(y)
+		points[i] = bendigo.NewVec(y + noise*rnd.NormFloat64())
+	}
+	return points, exact
+}
+
+// rmsDeviation returns the root mean square distance of the spline at the knots from the locations
+func rmsDeviation(spline bendigo.Spline, locs []bendigo.Vec) float64 {
+	sum := 0.
+	for i, loc := range locs {
+		t, _ := spline.Knots().Knot(i)
+		dist := spline.At(t).Sub(loc).Len()
+		sum += dist * dist
+	}
+	return math.Sqrt(sum / float64(len(locs)))
+}
+
+func TestSmoothingVertBuilder_Interpolates(t *testing.T) {
+	tknots := []float64{0, 1, 3, 3.5, 5}
+	points := []bendigo.Vec{bendigo.NewVec(0, 0), bendigo.NewVec(1, 2), bendigo.NewVec(3, -1),
+		bendigo.NewVec(2, 2), bendigo.NewVec(4, 0)}
+	vertices := make([]*EnexVertex, len(points))
+	for i, p := range points {
+		vertices[i] = NewRawHermiteVertex(p)
+	}
+	sb := NewSmoothingVertBuilder(tknots, points, nil, 0)
+	bendigotest.AssertSplinesEqual(t, NewNaturalVertBuilder(tknots, vertices...).Spline(), sb.Spline(), 50)
+}
+
+func TestSmoothingVertBuilder_Line(t *testing.T) {
+	tknots := []float64{0, 1, 2, 4, 5}
+	points := []bendigo.Vec{bendigo.NewVec(0, 1), bendigo.NewVec(1, 2), bendigo.NewVec(2, 0),
+		bendigo.NewVec(4, 3), bendigo.NewVec(5, 2)}
+	weights := []float64{1, 2, 1, 1, 3}
+
+	// weighted least-squares line of the second dimension
+	var sw, st, sy, stt, sty float64
+	for i, p := range points {
+		w, x := weights[i], tknots[i]
+		sw, st, sy, stt, sty = sw+w, st+w*x, sy+w*p[1], stt+w*x*x, sty+w*x*p[1]
+	}
+	slope := (sw*sty - st*sy) / (sw*stt - st*st)
+	offset := (sy - slope*st) / sw
+
+	spline := NewSmoothingVertBuilder(tknots, points, weights, 1e12).Spline()
+	for i := 0; i <= 20; i++ {
+		at := 5 * float64(i) / 20
+		v := spline.At(at)
+		assert.InDelta(t, at, v[0], 1e-6, "linear dimension is kept")
+		assert.InDelta(t, offset+slope*at, v[1], 1e-6, "regression line at t = %v", at)
+	}
+}
+
+func TestSmoothingVertBuilder_Weights(t *testing.T) {
+	points, _ := createNoisySine(20, 0.3)
+	weights := make([]float64, len(points))
+	for i := range weights {
+		weights[i] = 1
+	}
+	weights[7] = 1e8
+	sb := NewSmoothingVertBuilder(nil, points, weights, 10)
+	spline := sb.Spline()
+	assert.InDelta(t, points[7][0], spline.At(7)[0], 1e-6, "heavy point is interpolated")
+	assert.Greater(t, math.Abs(points[8][0]-spline.At(8)[0]), 1e-3, "others are smoothed")
+}
+
+func TestSmoothingVertBuilder_GCV(t *testing.T) {
+	points, exact := createNoisySine(101, 0.2)
+	sb := NewSmoothingVertBuilder(nil, points, nil, 0)
+	lambda := sb.GCVLambda()
+	assert.Greater(t, lambda, 0.)
+	assert.True(t, math.IsInf(sb.GCV(0), 1))
+	assert.LessOrEqual(t, sb.GCV(lambda), sb.GCV(lambda/10))
+	assert.LessOrEqual(t, sb.GCV(lambda), sb.GCV(lambda*10))
+
+	// smoothing with the GCV lambda is closer to the exact sine than the noisy points and the extremes
+	sb.SetLambda(lambda)
+	smoothed := rmsDeviation(sb.Spline(), exact)
+	assert.Less(t, smoothed, 0.1)
+	sb.SetLambda(lambda * 1e-4)
+	assert.Less(t, smoothed, rmsDeviation(sb.Spline(), exact), "less smoothing follows noise")
+	sb.SetLambda(lambda * 1e4)
+	assert.Less(t, smoothed, rmsDeviation(sb.Spline(), exact), "more smoothing flattens")
+}
+
+func TestSmoothingVertBuilder_FewPoints(t *testing.T) {
+	sb := NewSmoothingVertBuilder([]float64{1, 3}, []bendigo.Vec{bendigo.NewVec(0, 0), bendigo.NewVec(2, 4)}, nil, 5)
+	AssertSplineAt(t, sb.Spline(), 2, bendigo.NewVec(1, 2))
+	assert.Equal(t, 0., sb.GCVLambda())
+
+	sb = NewSmoothingVertBuilder(nil, []bendigo.Vec{bendigo.NewVec(1, 1)}, nil, 5)
+	AssertSplineAt(t, sb.Spline(), 0, bendigo.NewVec(1, 1))
+}
+
+func TestSmoothingVertBuilder_InvalidKnots(t *testing.T) {
+	points := []bendigo.Vec{bendigo.NewVec(0, 0), bendigo.NewVec(1, 1), bendigo.NewVec(2, 0)}
+	assert.Panics(t, func() { NewSmoothingVertBuilder([]float64{0, 1}, points, nil, 1) }, "length mismatch")
+	assert.Panics(t, func() { NewSmoothingVertBuilder([]float64{0, 1, 1}, points, nil, 1) }, "repeated knot")
+	assert.Panics(t, func() { NewSmoothingVertBuilder([]float64{0, 2, 1}, points, nil, 1) }, "decreasing knots")
+}
@@ -0,0 +1,151 @@
+package cubic
+
+import (
+	"fmt"
+	"github.com/walpod/bendigo"
+)
+
+// TCB contains the Kochanek-Bartels parameters of a vertex, all zero results in a Catmull-Rom spline
+type TCB struct {
+	Tension    float64 // > 0: shorter tangents (tighter curve), < 0: longer tangents (rounder curve)
+	Continuity float64 // != 0: entry and exit tangents differ, creating corners
+	Bias       float64 // > 0: tangent leans towards the previous vertex (overshoot), < 0: towards the next one
+}
+
+// TCBVertBuilder is an hermite vertex-based builder for Kochanek-Bartels splines with tension, continuity and bias
+// per vertex
+type TCBVertBuilder struct {
+	HermiteVertBuilder
+	tcbs []TCB
+}
+
+// NewTCBVertBuilder creates a builder, tcbs: nil = all zero, else one for each vertex
+func NewTCBVertBuilder(tknots []float64, tcbs []TCB, vertices ...*EnexVertex) *TCBVertBuilder {
+	if tcbs == nil {
+		tcbs = make([]TCB, len(vertices))
+	} else if len(tcbs) != len(vertices) {
+		panic("tcbs and vertices must have same length")
+	}
+	sb := &TCBVertBuilder{
+		HermiteVertBuilder: *NewHermiteVertBuilder(tknots, vertices...),
+		tcbs:               tcbs}
+	sb.CalcTangents()
+	return sb
+}
+
+// TCB returns the parameters of the vertex with given no.
+func (sb *TCBVertBuilder) TCB(knotNo int) (tcb TCB, err error) {
+	if knotNo < 0 || knotNo >= len(sb.tcbs) {
+		return TCB{}, fmt.Errorf("knotNo %v does not exist", knotNo)
+	}
+	return sb.tcbs[knotNo], nil
+}
+
+func (sb *TCBVertBuilder) SetTCB(knotNo int, tcb TCB) (err error) {
+	if knotNo < 0 || knotNo >= len(sb.tcbs) {
+		return fmt.Errorf("knotNo %v does not exist", knotNo)
+	}
+	sb.tcbs[knotNo] = tcb
+	sb.CalcTangents() // TODO recalculate only around updated knot
+	return nil
+}
+
+// SetParameterization derives the knots from the vertex locations, see HermiteVertBuilder.SetParameterization
+func (sb *TCBVertBuilder) SetParameterization(parameterization bendigo.Parameterization) {
+	sb.HermiteVertBuilder.SetParameterization(parameterization)
+	sb.CalcTangents()
+}
+
+// SetClosed adds or removes the closing segment and recalculates the tangents, see HermiteVertBuilder.SetClosed
+func (sb *TCBVertBuilder) SetClosed(closed bool) {
+	sb.HermiteVertBuilder.SetClosed(closed)
+	sb.CalcTangents()
+}
+
+// AddVertex adds a vertex with all parameters zero
+func (sb *TCBVertBuilder) AddVertex(knotNo int, vertex bendigo.Vertex) (err error) {
+	return sb.AddTCBVertex(knotNo, vertex, TCB{})
+}
+
+// AddTCBVertex adds a vertex with given parameters
+func (sb *TCBVertBuilder) AddTCBVertex(knotNo int, vertex bendigo.Vertex, tcb TCB) (err error) {
+	err = sb.HermiteVertBuilder.AddVertex(knotNo, vertex)
+	if err == nil {
+		sb.tcbs = append(sb.tcbs, TCB{})
+		copy(sb.tcbs[knotNo+1:], sb.tcbs[knotNo:])
+		sb.tcbs[knotNo] = tcb
+		sb.CalcTangents() // TODO recalculate only around new knot
+	}
+	return err
+}
+
+// SplitAt inserts a vertex with all parameters zero at parameter t without changing the shape of the spline,
+// the tangents are recalculated on the next modification
+func (sb *TCBVertBuilder) SplitAt(t float64) (knotNo int, err error) {
+	vertexCnt := len(sb.vertices)
+	knotNo, err = sb.HermiteVertBuilder.SplitAt(t)
+	if err == nil && len(sb.vertices) > vertexCnt {
+		sb.tcbs = append(sb.tcbs, TCB{})
+		copy(sb.tcbs[knotNo+1:], sb.tcbs[knotNo:])
+		sb.tcbs[knotNo] = TCB{}
+	}
+	return knotNo, err
+}
+
+// UpdateVertex updates the vertex, keeping its parameters
+func (sb *TCBVertBuilder) UpdateVertex(knotNo int, vertex bendigo.Vertex) (err error) {
+	err = sb.HermiteVertBuilder.UpdateVertex(knotNo, vertex)
+	if err == nil {
+		sb.CalcTangents() // TODO recalculate only around updated knot
+	}
+	return err
+}
+
+func (sb *TCBVertBuilder) DeleteVertex(knotNo int) (err error) {
+	err = sb.HermiteVertBuilder.DeleteVertex(knotNo)
+	if err == nil {
+		sb.tcbs = append(sb.tcbs[:knotNo], sb.tcbs[knotNo+1:]...)
+		sb.CalcTangents() // TODO recalculate only around deleted knot
+	}
+	return err
+}
+
+// CalcTangents calculates and sets the entry and exit tangents of the hermite vertices.
+// The first and last vertex of an open spline are handled as if their missing neighbor coincided with them
+func (sb *TCBVertBuilder) CalcTangents() {
+	n := len(sb.vertices)
+	if n < 2 {
+		return
+	}
+	dim := sb.vertices[0].loc.Dim()
+
+	// calculate tangents for uniform case
+	setUniformTCBTangents := func(vt, vtprev, vtnext *EnexVertex, tcb TCB) {
+		tm, cm, cp, bm, bp := 1-tcb.Tension, 1-tcb.Continuity, 1+tcb.Continuity, 1-tcb.Bias, 1+tcb.Bias
+		entry, exit := bendigo.NewZeroVec(dim), bendigo.NewZeroVec(dim)
+		for d := 0; d < dim; d++ {
+			incoming, outgoing := vt.loc[d]-vtprev.loc[d], vtnext.loc[d]-vt.loc[d]
+			entry[d] = tm*cm*bp/2*incoming + tm*cp*bm/2*outgoing
+			exit[d] = tm*cp*bp/2*incoming + tm*cm*bm/2*outgoing
+		}
+		vt.entry, vt.exit = entry, exit
+	}
+
+	for i := 0; i < n; i++ {
+		vprev, vnext := sb.neighborVertices(i)
+		setUniformTCBTangents(sb.vertices[i], vprev, vnext, sb.tcbs[i])
+	}
+
+	// handle non-uniform case: same direction but lengths according to segment-length
+	if !sb.knots.IsUniform() {
+		for i := 0; i < sb.knots.SegmentCnt(); i++ {
+			segmentLen, _ := sb.knots.SegmentLen(i)
+			if segmentLen != 0 {
+				scf := 1 / segmentLen
+				vstart, vend := sb.segmentVertices(i)
+				vstart.exit = vstart.exit.Scale(scf)
+				vend.entry = vend.entry.Scale(scf)
+			}
+		}
+	}
+}
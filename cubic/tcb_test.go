@@ -0,0 +1,93 @@
+package cubic
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"testing"
+)
+
+func createTCBVertices() []*EnexVertex {
+	return []*EnexVertex{
+		NewRawHermiteVertex(bendigo.NewVec(0, 0)),
+		NewRawHermiteVertex(bendigo.NewVec(1, 2)),
+		NewRawHermiteVertex(bendigo.NewVec(3, 2)),
+		NewRawHermiteVertex(bendigo.NewVec(4, 0)),
+	}
+}
+
+func TestTCBVertBuilder_CatmullRom(t *testing.T) {
+	// all parameters zero equals catmull-rom
+	for _, tknots := range [][]float64{nil, {0, 1, 3, 3.5}} {
+		tcb := NewTCBVertBuilder(tknots, nil, createTCBVertices()...)
+		cr := NewCatmullRomVertBuilder(tknots, createTCBVertices()...)
+		bendigotest.AssertSplinesEqual(t, cr.Spline(), tcb.Spline(), 30)
+	}
+}
+
+func TestTCBVertBuilder_Tangents(t *testing.T) {
+	tcbs := []TCB{{}, {Tension: 1}, {Continuity: 0.5}, {Bias: 1}}
+	sb := NewTCBVertBuilder(nil, tcbs, createTCBVertices()...)
+
+	// tension 1: zero tangents
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), sb.vertices[1].Entry(), "entry with tension 1")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), sb.vertices[1].Exit(), "exit with tension 1")
+
+	// continuity: incoming (2,0), outgoing (1,-2)
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0.25*2+0.75*1, 0.75*-2), sb.vertices[2].Entry(), "entry with continuity")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0.75*2+0.25*1, 0.25*-2), sb.vertices[2].Exit(), "exit with continuity")
+
+	// bias 1: only incoming direction (1,-2), the end has no outgoing one
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, -2), sb.vertices[3].Entry(), "entry with bias")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, -2), sb.vertices[3].Exit(), "exit with bias")
+
+	// interpolates the vertices
+	for i, vt := range createTCBVertices() {
+		AssertSplineAt(t, sb.Spline(), float64(i), vt.Loc())
+	}
+
+	// non-uniform: tangents are scaled by the adjacent segment length
+	nsb := NewTCBVertBuilder([]float64{0, 1, 3, 3.5}, tcbs, createTCBVertices()...)
+	bendigotest.AssertVecInDelta(t, sb.vertices[2].Entry().Scale(0.5), nsb.vertices[2].Entry(), "non-uniform entry")
+	bendigotest.AssertVecInDelta(t, sb.vertices[2].Exit().Scale(2), nsb.vertices[2].Exit(), "non-uniform exit")
+}
+
+func TestTCBVertBuilder_Vertices(t *testing.T) {
+	sb := NewTCBVertBuilder(nil, nil, createTCBVertices()...)
+	assert.Nil(t, sb.AddTCBVertex(1, NewRawHermiteVertex(bendigo.NewVec(1, 0)), TCB{Continuity: 1}))
+	tcb, err := sb.TCB(1)
+	assert.Nil(t, err)
+	assert.Equal(t, TCB{Continuity: 1}, tcb)
+	assert.Equal(t, 5, len(sb.tcbs))
+	assert.NotEqual(t, sb.vertices[1].Entry(), sb.vertices[1].Exit())
+
+	assert.Nil(t, sb.SetTCB(2, TCB{Tension: 1}))
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), sb.vertices[2].Exit(), "exit after set tension")
+	assert.NotNil(t, sb.SetTCB(5, TCB{}))
+
+	assert.Nil(t, sb.UpdateVertex(2, NewRawHermiteVertex(bendigo.NewVec(1, 3))))
+	tcb, _ = sb.TCB(2)
+	assert.Equal(t, TCB{Tension: 1}, tcb, "update keeps parameters")
+
+	assert.Nil(t, sb.DeleteVertex(1))
+	assert.Equal(t, []TCB{{}, {Tension: 1}, {}, {}}, sb.tcbs)
+	_, err = sb.TCB(4)
+	assert.NotNil(t, err)
+}
+
+func TestTCBVertBuilder_SplitAt(t *testing.T) {
+	sb := NewTCBVertBuilder(nil, []TCB{{}, {Tension: 1}, {}, {}}, createTCBVertices()...)
+	before := sb.Spline()
+	knotNo, err := sb.SplitAt(0.5)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, knotNo)
+	assert.Equal(t, []TCB{{}, {}, {Tension: 1}, {}, {}}, sb.tcbs, "parameters follow their vertices")
+	AssertUniformSplitKeepsShape(t, before, sb.Spline(), 0, 0.5)
+
+	// editing after the split recalculates all tangents
+	assert.Nil(t, sb.UpdateVertex(4, NewRawHermiteVertex(bendigo.NewVec(4, 1))))
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(0, 0), sb.vertices[2].Exit(), "tension kept by shifted vertex")
+	_, err = sb.SplitAt(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(sb.tcbs), "split at existing knot adds no parameters")
+}
@@ -0,0 +1,167 @@
+package cubic
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+)
+
+// solveTridiagonal solves the tridiagonal linear equations a[i]*x[i-1] + b[i]*x[i] + c[i]*x[i+1] = d[i]
+// using the Thomas algorithm, a[0] and c[n-1] are ignored. The matrix must be non-singular without pivoting,
+// e.g. diagonally dominant
+func solveTridiagonal(a, b, c, d []float64) []float64 {
+	n := len(d)
+	if n == 0 {
+		return nil
+	}
+	cp := make([]float64, n) // modified super-diagonal
+	x := make([]float64, n)
+
+	// forward elimination
+	cp[0] = c[0] / b[0]
+	x[0] = d[0] / b[0]
+	for i := 1; i < n; i++ {
+		r := b[i] - a[i]*cp[i-1]
+		if i < n-1 {
+			cp[i] = c[i] / r
+		}
+		x[i] = (d[i] - a[i]*x[i-1]) / r
+	}
+
+	// backward substitution
+	for i := n - 2; i >= 0; i-- {
+		x[i] -= cp[i] * x[i+1]
+	}
+	return x
+}
+
+// solveCyclicTridiagonal solves tridiagonal linear equations with additional corner elements, i.e. the indices
+// wrap around: a[0] is the coefficient of x[n-1] in the first and c[n-1] of x[0] in the last equation.
+// The corners are handled by the Sherman-Morrison formula, less than 3 equations are solved directly.
+// An error is returned if the equations are recognized as singular
+func solveCyclicTridiagonal(a, b, c, d []float64) ([]float64, error) {
+	n := len(d)
+	if n < 3 {
+		return solveCyclicDense(a, b, c, d)
+	}
+	alpha, beta := c[n-1], a[0] // bottom-left and top-right corner
+	gamma := -b[0]
+
+	// modified tridiagonal matrix
+	bb := make([]float64, n)
+	copy(bb, b)
+	bb[0] = b[0] - gamma
+	bb[n-1] = b[n-1] - alpha*beta/gamma
+	x := solveTridiagonal(a, bb, c, d)
+
+	// correction
+	u := make([]float64, n)
+	u[0], u[n-1] = gamma, alpha
+	z := solveTridiagonal(a, bb, c, u)
+	denom := 1 + z[0] + beta*z[n-1]/gamma
+	if denom == 0 {
+		return nil, errors.New("cyclic tridiagonal equations are singular")
+	}
+	fact := (x[0] + beta*x[n-1]/gamma) / denom
+	for i := range x {
+		x[i] -= fact * z[i]
+	}
+	return x, nil
+}
+
+// solveCyclicDense solves small cyclic tridiagonal equations, where the wrapped indices coincide
+func solveCyclicDense(a, b, c, d []float64) ([]float64, error) {
+	n := len(d)
+	if n == 0 {
+		return nil, nil
+	}
+	am := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		am.Set(i, (i+n-1)%n, am.At(i, (i+n-1)%n)+a[i])
+		am.Set(i, i, am.At(i, i)+b[i])
+		am.Set(i, (i+1)%n, am.At(i, (i+1)%n)+c[i])
+	}
+	var x mat.VecDense
+	if err := x.SolveVec(am, mat.NewVecDense(n, d)); err != nil {
+		return nil, err
+	}
+	return x.RawVector().Data, nil
+}
+
+// pentadiagonalLDL is the LDL^T factorization of a symmetric positive definite matrix with two off-diagonals,
+// with unit lower triangular L, l1[i] = L[i+1][i], l2[i] = L[i+2][i], and diagonal D = d
+type pentadiagonalLDL struct {
+	d, l1, l2 []float64
+}
+
+// factorPentadiagonal factorizes the symmetric matrix with diagonal a, first off-diagonal b[i] = A[i][i+1] and
+// second off-diagonal c[i] = A[i][i+2], the last elements of b and c are ignored. No pivoting is needed for
+// positive definite matrices
+func factorPentadiagonal(a, b, c []float64) *pentadiagonalLDL {
+	n := len(a)
+	f := &pentadiagonalLDL{d: make([]float64, n), l1: make([]float64, n), l2: make([]float64, n)}
+	for i := 0; i < n; i++ {
+		f.d[i] = a[i]
+		if i >= 1 {
+			f.d[i] -= f.l1[i-1] * f.l1[i-1] * f.d[i-1]
+		}
+		if i >= 2 {
+			f.d[i] -= f.l2[i-2] * f.l2[i-2] * f.d[i-2]
+		}
+		if i+1 < n {
+			f.l1[i] = b[i]
+			if i >= 1 {
+				f.l1[i] -= f.l1[i-1] * f.d[i-1] * f.l2[i-1]
+			}
+			f.l1[i] /= f.d[i]
+		}
+		if i+2 < n {
+			f.l2[i] = c[i] / f.d[i]
+		}
+	}
+	return f
+}
+
+// solve solves the factorized equations for the right hand side rhs
+func (f *pentadiagonalLDL) solve(rhs []float64) []float64 {
+	n := len(rhs)
+	x := make([]float64, n)
+
+	// forward substitution with L and scaling by D
+	for i := 0; i < n; i++ {
+		x[i] = rhs[i]
+		if i >= 1 {
+			x[i] -= f.l1[i-1] * x[i-1]
+		}
+		if i >= 2 {
+			x[i] -= f.l2[i-2] * x[i-2]
+		}
+	}
+	for i := range x {
+		x[i] /= f.d[i]
+	}
+
+	// backward substitution with L^T
+	for i := n - 1; i >= 0; i-- {
+		if i+1 < n {
+			x[i] -= f.l1[i] * x[i+1]
+		}
+		if i+2 < n {
+			x[i] -= f.l2[i] * x[i+2]
+		}
+	}
+	return x
+}
+
+// inverseBand calculates the band of the inverse matrix within the pentadiagonal pattern without the full inverse:
+// s0[i] = inv[i][i], s1[i] = inv[i][i+1], s2[i] = inv[i][i+2]. See "A fast procedure for calculating minimum
+// cross-validation cubic smoothing splines" (M. F. Hutchinson, F. R. de Hoog)
+func (f *pentadiagonalLDL) inverseBand() (s0, s1, s2 []float64) {
+	n := len(f.d)
+	s0, s1, s2 = make([]float64, n+2), make([]float64, n+2), make([]float64, n+2) // padded with zeros
+	for i := n - 1; i >= 0; i-- {
+		s2[i] = -f.l1[i]*s1[i+1] - f.l2[i]*s0[i+2]
+		s1[i] = -f.l1[i]*s0[i+1] - f.l2[i]*s1[i+1]
+		s0[i] = 1/f.d[i] - f.l1[i]*s1[i] - f.l2[i]*s2[i]
+	}
+	return s0[:n], s1[:n], s2[:n]
+}
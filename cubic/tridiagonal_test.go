@@ -0,0 +1,90 @@
+package cubic
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/mat"
+	"testing"
+)
+
+func TestSolveTridiagonal(t *testing.T) {
+	a := []float64{0, 1, 1, 2}
+	b := []float64{4, 4, 5, 3}
+	c := []float64{1, 2, 1, 0}
+	x := []float64{1, -2, 3, 0.5}
+	d := make([]float64, len(x))
+	for i := range x {
+		d[i] = b[i] * x[i]
+		if i > 0 {
+			d[i] += a[i] * x[i-1]
+		}
+		if i < len(x)-1 {
+			d[i] += c[i] * x[i+1]
+		}
+	}
+	assert.InDeltaSlice(t, x, solveTridiagonal(a, b, c, d), delta)
+	assert.Empty(t, solveTridiagonal(nil, nil, nil, nil))
+}
+
+func TestSolveCyclicTridiagonal(t *testing.T) {
+	a := []float64{1, 1, 1, 2, 1}
+	b := []float64{4, 4, 5, 6, 4}
+	c := []float64{1, 2, 1, 1, 2}
+	x := []float64{1, -2, 3, 0.5, -1}
+	n := len(x)
+	d := make([]float64, n)
+	for i := range x {
+		d[i] = a[i]*x[(i+n-1)%n] + b[i]*x[i] + c[i]*x[(i+1)%n]
+	}
+	actual, err := solveCyclicTridiagonal(a, b, c, d)
+	assert.Nil(t, err)
+	assert.InDeltaSlice(t, x, actual, delta)
+}
+
+func TestSolveCyclicTridiagonal_Small(t *testing.T) {
+	// two equations: the wrapped neighbors coincide
+	x, err := solveCyclicTridiagonal([]float64{1, 1}, []float64{4, 4}, []float64{1, 1}, []float64{4 + 2*2, 2 + 4*2})
+	assert.Nil(t, err)
+	assert.InDeltaSlice(t, []float64{1, 2}, x, delta)
+	x, err = solveCyclicTridiagonal([]float64{1}, []float64{4}, []float64{1}, []float64{12})
+	assert.Nil(t, err)
+	assert.InDeltaSlice(t, []float64{2}, x, delta)
+
+	_, err = solveCyclicTridiagonal([]float64{1, 1}, []float64{2, 2}, []float64{1, 1}, []float64{1, 1})
+	assert.NotNil(t, err, "singular")
+}
+
+func TestPentadiagonalLDL(t *testing.T) {
+	a := []float64{6, 7, 8, 6, 9, 7}
+	b := []float64{1, -2, 1, 2, -1, 0}
+	c := []float64{0.5, 1, -1, 0.5, 0, 0}
+	n := len(a)
+	am := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		am.SetSym(i, i, a[i])
+		if i+1 < n {
+			am.SetSym(i, i+1, b[i])
+		}
+		if i+2 < n {
+			am.SetSym(i, i+2, c[i])
+		}
+	}
+	f := factorPentadiagonal(a, b, c)
+
+	x := []float64{1, -2, 3, 0.5, -1, 2}
+	var d mat.VecDense
+	d.MulVec(am, mat.NewVecDense(n, x))
+	assert.InDeltaSlice(t, x, f.solve(d.RawVector().Data), delta)
+
+	var inv mat.Dense
+	assert.Nil(t, inv.Inverse(am))
+	s0, s1, s2 := f.inverseBand()
+	for i := 0; i < n; i++ {
+		assert.InDelta(t, inv.At(i, i), s0[i], delta)
+		if i+1 < n {
+			assert.InDelta(t, inv.At(i, i+1), s1[i], delta)
+		}
+		if i+2 < n {
+			assert.InDelta(t, inv.At(i, i+2), s2[i], delta)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+// Package fit approximates point clouds by cubic splines
+package fit
+
+import (
+	"errors"
+	"fmt"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/cubic"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// degree of the fitted b-splines, a fit with k domain knots has k + 2 controls
+const degree = 3
+
+// Params controls the iterative parameter correction of least-squares fits
+type Params struct {
+	MaxIterations int     // max. number of parameter corrections after the initial chord-length fit
+	Tolerance     float64 // stop correcting if the rms error improves by less
+}
+
+func NewParams(maxIterations int, tolerance float64) *Params {
+	return &Params{MaxIterations: maxIterations, Tolerance: tolerance}
+}
+
+// DefaultParams corrects the parameters up to 50 times, each correction converges linearly
+func DefaultParams() *Params {
+	return NewParams(50, 1e-12)
+}
+
+// Bezier fits a bezier builder with vertexCnt vertices to the points, see LeastSquares
+func Bezier(points []bendigo.Vec, vertexCnt int, params *Params) (*cubic.BezierVertBuilder, error) {
+	ls, err := LeastSquares(points, vertexCnt, params)
+	if err != nil {
+		return nil, err
+	}
+	return ls.Bezier(), nil
+}
+
+// BezierToKnots fits a bezier builder with given knots to the points, see LeastSquaresToKnots
+func BezierToKnots(points []bendigo.Vec, knots bendigo.Knots, params *Params) (*cubic.BezierVertBuilder, error) {
+	ls, err := LeastSquaresToKnots(points, knots, params)
+	if err != nil {
+		return nil, err
+	}
+	return ls.Bezier(), nil
+}
+
+// Hermite fits an hermite builder with vertexCnt vertices to the points, see LeastSquares
+func Hermite(points []bendigo.Vec, vertexCnt int, params *Params) (*cubic.HermiteVertBuilder, error) {
+	ls, err := LeastSquares(points, vertexCnt, params)
+	if err != nil {
+		return nil, err
+	}
+	return ls.Bezier().Hermite(), nil
+}
+
+// HermiteToKnots fits an hermite builder with given knots to the points, see LeastSquaresToKnots
+func HermiteToKnots(points []bendigo.Vec, knots bendigo.Knots, params *Params) (*cubic.HermiteVertBuilder, error) {
+	ls, err := LeastSquaresToKnots(points, knots, params)
+	if err != nil {
+		return nil, err
+	}
+	return ls.Bezier().Hermite(), nil
+}
+
+// LeastSquaresFit is a C2-continuous cubic spline approximating a sequence of points. It starts at the first and
+// ends at the last point, in between it minimizes the sum of squared distances between each point and the spline
+// at the parameter assigned to the point
+type LeastSquaresFit struct {
+	points  []bendigo.Vec
+	ts      []float64 // parameter of each point
+	bspline *cubic.BSplineVertBuilder
+	rmsErr  float64
+}
+
+// LeastSquares fits a spline with vertexCnt vertices (vertexCnt - 1 segments) to the points. The parameters of the
+// points are estimated by chord length on the domain [0,1], the knots are placed so that each segment
+// covers about the same number of points
+func LeastSquares(points []bendigo.Vec, vertexCnt int, params *Params) (*LeastSquaresFit, error) {
+	if vertexCnt < 2 {
+		return nil, fmt.Errorf("at least 2 vertices required, got %v", vertexCnt)
+	}
+	if err := checkPointCnt(len(points), vertexCnt); err != nil {
+		return nil, err
+	}
+	ts := ChordLengthParams(points, 0, 1)
+	return fitLeastSquares(points, ts, averagedKnots(ts, vertexCnt), false, params)
+}
+
+// LeastSquaresToKnots fits a spline with given knots to the points. The parameters of the points are estimated by
+// chord length on the domain of the knots
+func LeastSquaresToKnots(points []bendigo.Vec, knots bendigo.Knots, params *Params) (*LeastSquaresFit, error) {
+	if knots.KnotCnt() < 2 {
+		return nil, fmt.Errorf("at least 2 knots required, got %v", knots.KnotCnt())
+	}
+	tknots := knots.External()
+	if knots.IsUniform() {
+		tknots = make([]float64, knots.KnotCnt())
+		for i := range tknots {
+			tknots[i], _ = knots.Knot(i)
+		}
+	}
+	ts := ChordLengthParams(points, knots.Tstart(), knots.Tend())
+	return fitLeastSquares(points, ts, tknots, knots.IsUniform(), params)
+}
+
+// checkPointCnt checks that there are enough points to determine the controls of a spline with knotCnt knots
+func checkPointCnt(pointCnt, knotCnt int) error {
+	if pointCnt < knotCnt+degree-1 {
+		return fmt.Errorf("%v points are too few to fit %v knots, at least %v required",
+			pointCnt, knotCnt, knotCnt+degree-1)
+	}
+	return nil
+}
+
+// fitLeastSquares fits the spline with the initial parameters ts and improves it by parameter correction
+func fitLeastSquares(points []bendigo.Vec, ts []float64, tknots []float64, uniform bool, params *Params) (*LeastSquaresFit, error) {
+	if err := checkPointCnt(len(points), len(tknots)); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		params = DefaultParams()
+	}
+
+	fit := &LeastSquaresFit{points: points, ts: ts}
+	if err := fit.solve(tknots, uniform); err != nil {
+		return nil, err
+	}
+	for i := 0; i < params.MaxIterations; i++ {
+		corrected := &LeastSquaresFit{points: points, ts: fit.correctedParams()}
+		if err := corrected.solve(tknots, uniform); err != nil {
+			return nil, err
+		}
+		improvement := fit.rmsErr - corrected.rmsErr
+		if improvement > 0 {
+			fit = corrected
+		}
+		if improvement < params.Tolerance {
+			break
+		}
+	}
+	return fit, nil
+}
+
+// solve determines the controls of the clamped b-spline with domain knots tknots for the current parameters,
+// uniform keeps the knots uniform in the resulting builder
+func (f *LeastSquaresFit) solve(tknots []float64, uniform bool) error {
+	m, k := len(f.points), len(tknots)+degree-1 // number of points and controls
+	dim := f.points[0].Dim()
+	knotVector := bendigo.ClampedKnotVector(degree, tknots)
+	first, last := f.points[0], f.points[m-1]
+
+	// the end controls are fixed to the end points, the inner ones are the unknowns for the inner points
+	controls := make([]*cubic.ControlVertex, k)
+	controls[0], controls[k-1] = cubic.NewControlVertex(first), cubic.NewControlVertex(last)
+	a := mat.NewDense(m-2, k-2, nil)
+	b := mat.NewDense(m-2, dim, nil)
+	for i := 1; i < m-1; i++ {
+		span := bendigo.KnotSpan(degree, knotVector, f.ts[i])
+		basis := bendigo.BasisFuncs(degree, knotVector, span, f.ts[i])
+		rhs := f.points[i]
+		for j, nj := range basis {
+			switch c := span - degree + j; c {
+			case 0:
+				rhs = rhs.Sub(first.Scale(nj))
+			case k - 1:
+				rhs = rhs.Sub(last.Scale(nj))
+			default:
+				a.Set(i-1, c-1, nj)
+			}
+		}
+		for d := 0; d < dim; d++ {
+			b.Set(i-1, d, rhs[d])
+		}
+	}
+
+	var x mat.Dense
+	if err := x.Solve(a, b); err != nil {
+		// an ill-conditioned system still has a usable solution, a singular one hasn't
+		if cond, ok := err.(mat.Condition); !ok || math.IsInf(float64(cond), 1) {
+			return errors.New("least squares system is singular, the points don't cover all segments")
+		}
+	}
+	for c := 1; c < k-1; c++ {
+		loc := bendigo.NewZeroVec(dim)
+		for d := 0; d < dim; d++ {
+			loc[d] = x.At(c-1, d)
+		}
+		controls[c] = cubic.NewControlVertex(loc)
+	}
+	if uniform {
+		f.bspline = cubic.NewBSplineVertBuilder(nil, true, controls...)
+	} else {
+		f.bspline = cubic.NewBSplineVertBuilder(tknots, true, controls...)
+	}
+
+	spline := f.bspline.Canonical()
+	sum := 0.
+	for i, p := range f.points {
+		dist := spline.At(f.ts[i]).Sub(p).Len()
+		sum += dist * dist
+	}
+	f.rmsErr = math.Sqrt(sum / float64(m))
+	return nil
+}
+
+// correctedParams moves the parameter of each inner point by one Newton step towards the closest point on the spline
+func (f *LeastSquaresFit) correctedParams() []float64 {
+	spline := f.bspline.Canonical()
+	tstart, tend := spline.Knots().Tstart(), spline.Knots().Tend()
+	ts := make([]float64, len(f.ts))
+	copy(ts, f.ts)
+	for i := 1; i < len(ts)-1; i++ {
+		ts[i] = cubic.NewtonParam(spline, f.points[i], ts[i], tstart, tend)
+	}
+	return ts
+}
+
+// Bezier converts the fitted spline into a bezier builder
+func (f *LeastSquaresFit) Bezier() *cubic.BezierVertBuilder {
+	return f.bspline.Bezier()
+}
+
+// BSpline returns the fitted spline as clamped b-spline
+func (f *LeastSquaresFit) BSpline() *cubic.BSplineVertBuilder {
+	return f.bspline
+}
+
+// Params returns a copy of the parameters assigned to the points
+func (f *LeastSquaresFit) Params() []float64 {
+	ts := make([]float64, len(f.ts))
+	copy(ts, f.ts)
+	return ts
+}
+
+// RMSError returns the root mean square distance between the points and the spline at their parameters
+func (f *LeastSquaresFit) RMSError() float64 {
+	return f.rmsErr
+}
+
+// ChordLengthParams assigns parameters to the points proportional to the accumulated distance between them,
+// the first point gets tstart and the last one tend
+func ChordLengthParams(points []bendigo.Vec, tstart, tend float64) []float64 {
+	ts := make([]float64, len(points))
+	if len(points) == 0 {
+		return ts
+	}
+	for i := 1; i < len(points); i++ {
+		ts[i] = ts[i-1] + points[i].Sub(points[i-1]).Len()
+	}
+	total := ts[len(ts)-1]
+	for i := range ts {
+		if total == 0 { // all points coincide: space evenly
+			ts[i] = tstart + (tend-tstart)*float64(i)/math.Max(1, float64(len(ts)-1))
+		} else {
+			ts[i] = tstart + (tend-tstart)*ts[i]/total
+		}
+	}
+	return ts
+}
+
+// averagedKnots places knotCnt knots between the first and last parameter so that each segment contains about the
+// same number of parameters, see "The NURBS Book" - 9.2.2 (Piegl, Tiller)
+func averagedKnots(ts []float64, knotCnt int) []float64 {
+	tknots := make([]float64, knotCnt)
+	m := len(ts)
+	tknots[0], tknots[knotCnt-1] = ts[0], ts[m-1]
+	step := float64(m-1) / float64(knotCnt-1)
+	for j := 1; j < knotCnt-1; j++ {
+		pos := float64(j) * step
+		i := int(pos)
+		alpha := pos - float64(i)
+		tknots[j] = (1-alpha)*ts[i] + alpha*ts[i+1]
+	}
+	return tknots
+}
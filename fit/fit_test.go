@@ -0,0 +1,107 @@
+package fit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"github.com/walpod/bendigo/cubic"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// sampleBezierS samples an S-formed bezier segment at unevenly distributed parameters
+func sampleBezierS(cnt int) (*cubic.BezierVertBuilder, []bendigo.Vec) {
+	bez := cubic.NewBezierVertBuilder(nil,
+		cubic.NewBezierVertex(bendigo.NewVec(0, 0), nil, bendigo.NewVec(3, 0)),
+		cubic.NewBezierVertex(bendigo.NewVec(2, 2), bendigo.NewVec(-1, 2), nil),
+	)
+	spline := bez.Spline()
+	points := make([]bendigo.Vec, cnt)
+	for i := range points {
+		u := float64(i) / float64(cnt-1)
+		points[i] = spline.At(u * u)
+	}
+	return bez, points
+}
+
+func TestLeastSquares_ParameterCorrection(t *testing.T) {
+	bez, points := sampleBezierS(50)
+
+	chordOnly, err := LeastSquares(points, 2, NewParams(0, 0))
+	assert.Nil(t, err)
+	corrected, err := LeastSquares(points, 2, NewParams(100, 0))
+	assert.Nil(t, err)
+	assert.Less(t, corrected.RMSError(), chordOnly.RMSError()/1000, "parameter correction improves fit")
+	assert.Less(t, corrected.RMSError(), 1e-10)
+
+	// controls of the original segment are recovered
+	fitted := corrected.Bezier()
+	for i := 0; i < 2; i++ {
+		original, vertex := bez.BezierVertex(i), fitted.BezierVertex(i)
+		assert.InDelta(t, 0, original.Loc().Sub(vertex.Loc()).Len(), 1e-8)
+	}
+	assert.InDelta(t, 0, bendigo.NewVec(3, 0).Sub(fitted.BezierVertex(0).Exit()).Len(), 1e-8)
+
+	ts := corrected.Params()
+	assert.Equal(t, 0., ts[0])
+	assert.Equal(t, 1., ts[len(ts)-1])
+}
+
+func TestHermite_NoisySine(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	points := make([]bendigo.Vec, 500)
+	for i := range points {
+		x := 2 * math.Pi * float64(i) / float64(len(points)-1)
+		points[i] = bendigo.NewVec(x, math.Sin(x)+rnd.NormFloat64()*0.01)
+	}
+	points[0], points[len(points)-1] = bendigo.NewVec(0, 0), bendigo.NewVec(2*math.Pi, 0)
+
+	herm, err := Hermite(points, 8, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, herm.Knots().KnotCnt())
+	spline := herm.Spline()
+	for i := 0; i <= 100; i++ {
+		at := spline.Knots().Tstart() + (spline.Knots().Tend()-spline.Knots().Tstart())*float64(i)/100
+		p := spline.At(at)
+		assert.InDelta(t, math.Sin(p[0]), p[1], 0.02, "close to sine at x = %v", p[0])
+	}
+}
+
+func TestBezierToKnots(t *testing.T) {
+	_, points := sampleBezierS(40)
+	bez, err := BezierToKnots(points, bendigo.NewUniformKnots(4), nil)
+	assert.Nil(t, err)
+	assert.True(t, bez.Knots().IsUniform())
+	assert.Equal(t, 4, bez.Knots().KnotCnt())
+	bendigotest.AssertVecInDelta(t, points[0], bez.Spline().At(0), "starts at first point")
+	bendigotest.AssertVecInDelta(t, points[len(points)-1], bez.Spline().At(3), "ends at last point")
+
+	herm, err := HermiteToKnots(points, bendigo.NewNonUniformKnots([]float64{1, 2, 4}), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{1, 2, 4}, herm.Knots().External())
+	bendigotest.AssertVecInDelta(t, points[len(points)-1], herm.Spline().At(4), "ends at last point")
+}
+
+func TestLeastSquares_Errors(t *testing.T) {
+	_, points := sampleBezierS(5)
+	_, err := Bezier(points, 1, nil)
+	assert.NotNil(t, err, "too few vertices")
+	_, err = Bezier(points, 4, nil)
+	assert.NotNil(t, err, "too few points")
+	_, err = LeastSquares(nil, 2, nil)
+	assert.NotNil(t, err, "no points")
+	_, err = LeastSquares(points[:1], 2, nil)
+	assert.NotNil(t, err, "single point")
+	_, err = Bezier(points, 3, nil)
+	assert.Nil(t, err, "as many points as controls")
+	_, err = BezierToKnots(points, bendigo.NewUniformKnots(1), nil)
+	assert.NotNil(t, err, "too few knots")
+}
+
+func TestChordLengthParams(t *testing.T) {
+	ts := ChordLengthParams([]bendigo.Vec{bendigo.NewVec(0, 0), bendigo.NewVec(3, 4), bendigo.NewVec(3, 5)}, 1, 4)
+	assert.InDeltaSlice(t, []float64{1, 3.5, 4}, ts, bendigotest.Delta)
+	ts = ChordLengthParams([]bendigo.Vec{bendigo.NewVec(1), bendigo.NewVec(1), bendigo.NewVec(1)}, 0, 1)
+	assert.InDeltaSlice(t, []float64{0, 0.5, 1}, ts, bendigotest.Delta)
+}
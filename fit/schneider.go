@@ -0,0 +1,143 @@
+package fit
+
+import (
+	"errors"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bezier"
+	"github.com/walpod/bendigo/cubic"
+	"math"
+)
+
+// SchneiderParams controls the adaptive fitting of bezier segments
+type SchneiderParams struct {
+	MaxError      float64 // max. distance of each point to the curve at its parameter
+	CornerAngle   float64 // the polyline turning by more than this angle (radians) at a point makes it a corner, >= Pi: none
+	MaxIterations int     // max. number of parameter corrections before a segment is split
+}
+
+func NewSchneiderParams(maxError, cornerAngle float64) *SchneiderParams {
+	return &SchneiderParams{MaxError: maxError, CornerAngle: cornerAngle, MaxIterations: 4}
+}
+
+// DefaultSchneiderParams fits within 0.001 and detects corners turning by more than 60 degrees
+func DefaultSchneiderParams() *SchneiderParams {
+	return NewSchneiderParams(1e-3, math.Pi/3)
+}
+
+// Schneider fits the minimal number of cubic bezier segments within params.MaxError to the points, see
+// "An Algorithm for Automatically Fitting Digitized Curves" - Graphics Gems (P. J. Schneider).
+// The points are split into runs at corners, each run is fitted by one segment with least-squares tangent lengths,
+// which is improved by parameter correction and else split at the point of maximal error. Vertices between segments
+// of a run are G1-continuous, at corners entry and exit controls are independent. The knots are uniform
+func Schneider(points []bendigo.Vec, params *SchneiderParams) (*cubic.BezierVertBuilder, error) {
+	points = withoutDuplicates(points)
+	if len(points) < 2 {
+		return nil, errors.New("at least 2 distinct points required")
+	}
+	if params == nil {
+		params = DefaultSchneiderParams()
+	}
+
+	var segments [][4]bendigo.Vec
+	first := 0
+	for i := 1; i < len(points); i++ {
+		if i == len(points)-1 || isCorner(points, i, params.CornerAngle) {
+			startTan := unit(points[first+1].Sub(points[first]))
+			endTan := unit(points[i-1].Sub(points[i]))
+			segments = fitSchneiderRun(points, first, i, startTan, endTan, params, segments)
+			first = i
+		}
+	}
+
+	vertices := make([]*cubic.EnexVertex, len(segments)+1)
+	vertices[0] = cubic.NewBezierVertex(segments[0][0], nil, segments[0][1])
+	for i := 1; i < len(segments); i++ {
+		vertices[i] = cubic.NewBezierVertex(segments[i][0], segments[i-1][2], segments[i][1])
+	}
+	last := segments[len(segments)-1]
+	vertices[len(segments)] = cubic.NewBezierVertex(last[3], last[2], nil)
+	return cubic.NewBezierVertBuilder(nil, vertices...), nil
+}
+
+// fitSchneiderRun fits the points first ... last with given unit tangents at the ends (pointing into the run)
+// and appends the resulting segments
+func fitSchneiderRun(points []bendigo.Vec, first, last int, startTan, endTan bendigo.Vec, params *SchneiderParams,
+	segments [][4]bendigo.Vec) [][4]bendigo.Vec {
+
+	if last-first == 1 {
+		dist := points[last].Sub(points[first]).Len() / 3
+		return append(segments, [4]bendigo.Vec{points[first], points[first].Add(startTan.Scale(dist)),
+			points[last].Add(endTan.Scale(dist)), points[last]})
+	}
+
+	run := points[first : last+1]
+	ts := ChordLengthParams(run, 0, 1)
+	controls := cubic.FitBezierLengths(run, ts, startTan, endTan)
+	maxErr, splitNo := maxFitError(run, ts, controls)
+	if maxErr <= params.MaxError {
+		return append(segments, controls)
+	}
+
+	// close enough to be improved by parameter correction
+	if maxErr <= 4*params.MaxError {
+		for i := 0; i < params.MaxIterations; i++ {
+			sg := bezier.NewSegment(controls[:]...)
+			for j := 1; j < len(ts)-1; j++ {
+				ts[j] = cubic.NewtonParam(sg, run[j], ts[j], 0, 1)
+			}
+			controls = cubic.FitBezierLengths(run, ts, startTan, endTan)
+			maxErr, splitNo = maxFitError(run, ts, controls)
+			if maxErr <= params.MaxError {
+				return append(segments, controls)
+			}
+		}
+	}
+
+	// split at the point of maximal error with a common tangent on both sides
+	split := first + splitNo
+	centerTan := unit(points[split-1].Sub(points[split+1]))
+	if centerTan.Len() == 0 {
+		centerTan = unit(points[split-1].Sub(points[split]))
+	}
+	segments = fitSchneiderRun(points, first, split, startTan, centerTan, params, segments)
+	return fitSchneiderRun(points, split, last, centerTan.Negate(), endTan, params, segments)
+}
+
+// maxFitError returns the max. distance of the inner points to the bezier curve at their parameters and the index
+// of that point
+func maxFitError(points []bendigo.Vec, ts []float64, controls [4]bendigo.Vec) (maxErr float64, index int) {
+	sg := bezier.NewSegment(controls[:]...)
+	index = len(points) / 2
+	for i := 1; i < len(points)-1; i++ {
+		if dist := sg.At(ts[i]).Sub(points[i]).Len(); dist > maxErr {
+			maxErr, index = dist, i
+		}
+	}
+	return maxErr, index
+}
+
+// isCorner checks if the polyline turns by more than cornerAngle at point no. i
+func isCorner(points []bendigo.Vec, i int, cornerAngle float64) bool {
+	in, out := unit(points[i].Sub(points[i-1])), unit(points[i+1].Sub(points[i]))
+	return math.Acos(math.Max(-1, math.Min(1, in.Dot(out)))) > cornerAngle
+}
+
+// unit returns the vector scaled to length 1, the zero vector remains
+func unit(v bendigo.Vec) bendigo.Vec {
+	l := v.Len()
+	if l == 0 {
+		return v
+	}
+	return v.Scale(1 / l)
+}
+
+// withoutDuplicates removes consecutive points at the same location
+func withoutDuplicates(points []bendigo.Vec) []bendigo.Vec {
+	distinct := make([]bendigo.Vec, 0, len(points))
+	for i, p := range points {
+		if i == 0 || p.Sub(distinct[len(distinct)-1]).Len() > 0 {
+			distinct = append(distinct, p)
+		}
+	}
+	return distinct
+}
@@ -0,0 +1,93 @@
+package fit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"github.com/walpod/bendigo/cubic"
+	"math"
+	"testing"
+)
+
+// AssertPointsWithin checks that each point is within maxDist of the spline
+func AssertPointsWithin(t *testing.T, points []bendigo.Vec, bez *cubic.BezierVertBuilder, maxDist float64) {
+	spline := bez.Canonical()
+	for _, p := range points {
+		_, _, dist, err := spline.ClosestPoint(p)
+		assert.Nil(t, err)
+		assert.LessOrEqual(t, dist, maxDist, "point %v too far from curve", p)
+	}
+}
+
+// sampleStroke samples a wavy pen stroke
+func sampleStroke(cnt int) []bendigo.Vec {
+	points := make([]bendigo.Vec, cnt)
+	for i := range points {
+		x := 4 * math.Pi * float64(i) / float64(cnt-1)
+		points[i] = bendigo.NewVec(x, math.Sin(x)+0.3*math.Sin(3*x))
+	}
+	return points
+}
+
+func TestSchneider_ErrorTolerance(t *testing.T) {
+	points := sampleStroke(200)
+	coarse, err := Schneider(points, NewSchneiderParams(0.05, math.Pi))
+	assert.Nil(t, err)
+	AssertPointsWithin(t, points, coarse, 0.05)
+	fine, err := Schneider(points, NewSchneiderParams(0.001, math.Pi))
+	assert.Nil(t, err)
+	AssertPointsWithin(t, points, fine, 0.001)
+	assert.Greater(t, fine.Knots().SegmentCnt(), coarse.Knots().SegmentCnt(), "smaller error needs more segments")
+	assert.Less(t, coarse.Knots().SegmentCnt(), 20, "compact")
+
+	// G1-continuous vertices: entry and exit control are on opposite sides in the same direction
+	for _, vertex := range bendigo.Vertices(fine) {
+		vt := vertex.(*cubic.EnexVertex)
+		entry, exit := unit(vt.Loc().Sub(vt.Entry())), unit(vt.Exit().Sub(vt.Loc()))
+		assert.InDelta(t, 1, entry.Dot(exit), 1e-9, "G1 at %v", vt.Loc())
+	}
+
+	// ends match first and last point
+	spline := fine.Spline()
+	assert.InDelta(t, 0, spline.At(0).Sub(points[0]).Len(), bendigotest.Delta)
+	assert.InDelta(t, 0, spline.At(spline.Knots().Tend()).Sub(points[len(points)-1]).Len(), bendigotest.Delta)
+}
+
+func TestSchneider_Corners(t *testing.T) {
+	// L-shaped stroke with a corner at (5,0), consecutive duplicates are ignored
+	var points []bendigo.Vec
+	for i := 0; i <= 10; i++ {
+		points = append(points, bendigo.NewVec(float64(i)/2, 0))
+	}
+	points = append(points, bendigo.NewVec(5, 0))
+	for i := 1; i <= 10; i++ {
+		points = append(points, bendigo.NewVec(5, float64(i)/2))
+	}
+
+	bez, err := Schneider(points, NewSchneiderParams(0.01, math.Pi/4))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, bez.Knots().SegmentCnt(), "one straight segment per side")
+	AssertPointsWithin(t, points, bez, 0.01)
+	defaulted, err := Schneider(points, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, defaulted.Knots().SegmentCnt(), "corner detected by default params")
+	corner := bez.BezierVertex(1)
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(5, 0), corner.Loc(), "corner vertex")
+	assert.InDelta(t, 0, corner.Entry()[1], bendigotest.Delta, "entry along first side")
+	assert.InDelta(t, 5, corner.Exit()[0], bendigotest.Delta, "exit along second side")
+
+	// without corner detection the corner is rounded by more segments
+	rounded, err := Schneider(points, NewSchneiderParams(0.01, math.Pi))
+	assert.Nil(t, err)
+	assert.Greater(t, rounded.Knots().SegmentCnt(), 2)
+	AssertPointsWithin(t, points, rounded, 0.01)
+}
+
+func TestSchneider_FewPoints(t *testing.T) {
+	_, err := Schneider([]bendigo.Vec{bendigo.NewVec(1, 1), bendigo.NewVec(1, 1)}, NewSchneiderParams(0.1, math.Pi))
+	assert.NotNil(t, err, "only one distinct point")
+
+	bez, err := Schneider([]bendigo.Vec{bendigo.NewVec(0, 0), bendigo.NewVec(3, 0)}, NewSchneiderParams(0.1, math.Pi))
+	assert.Nil(t, err)
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 0), bez.BezierVertex(0).Exit(), "third of the chord")
+}
@@ -0,0 +1,128 @@
+package bendigo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// geometryEps is the threshold below which velocity or normal acceleration are treated as vanishing
+const geometryEps = 1e-12
+
+// derivs returns the derivatives of order 1 up to maxOrder at t
+func derivs(spline Differentiable, t float64, maxOrder int) ([]Vec, error) {
+	ds := make([]Vec, maxOrder)
+	for o := 1; o <= maxOrder; o++ {
+		ds[o-1] = spline.Deriv(t, o)
+		if ds[o-1] == nil {
+			return nil, fmt.Errorf("spline can't be evaluated at %v", t)
+		}
+	}
+	if ds[0].Len() <= geometryEps {
+		return nil, fmt.Errorf("velocity vanishes at %v, geometry is undefined", t)
+	}
+	return ds, nil
+}
+
+// normalAccel returns the component of acceleration a orthogonal to velocity v
+func normalAccel(v, a Vec) Vec {
+	return a.Sub(v.Scale(a.Dot(v) / v.Dot(v)))
+}
+
+// Tangent calculates the unit tangent vector at t
+func Tangent(spline Differentiable, t float64) (Vec, error) {
+	ds, err := derivs(spline, t, 1)
+	if err != nil {
+		return nil, err
+	}
+	return ds[0].Scale(1 / ds[0].Len()), nil
+}
+
+// Normal calculates the unit principal normal vector at t, which points to the center of curvature
+func Normal(spline Differentiable, t float64) (Vec, error) {
+	ds, err := derivs(spline, t, 2)
+	if err != nil {
+		return nil, err
+	}
+	an := normalAccel(ds[0], ds[1])
+	if an.Len() <= geometryEps {
+		return nil, fmt.Errorf("curvature vanishes at %v, normal is undefined", t)
+	}
+	return an.Scale(1 / an.Len()), nil
+}
+
+// Binormal calculates the unit binormal vector (tangent x normal) at t for 3-dimensional splines
+func Binormal(spline Differentiable, t float64) (Vec, error) {
+	frame, err := Frenet(spline, t)
+	if err != nil {
+		return nil, err
+	}
+	return frame.Binormal, nil
+}
+
+// FrenetFrame is the moving frame of a curve, consisting of unit tangent, normal and binormal (3D only, else nil)
+type FrenetFrame struct {
+	Tangent, Normal, Binormal Vec
+}
+
+// Frenet calculates the Frenet frame at t
+func Frenet(spline Differentiable, t float64) (*FrenetFrame, error) {
+	tan, err := Tangent(spline, t)
+	if err != nil {
+		return nil, err
+	}
+	nrm, err := Normal(spline, t)
+	if err != nil {
+		return nil, err
+	}
+	frame := &FrenetFrame{Tangent: tan, Normal: nrm}
+	if tan.Dim() == 3 {
+		frame.Binormal = tan.Cross(nrm)
+	}
+	return frame, nil
+}
+
+// Curvature calculates the (unsigned) curvature at t, valid for any dimension
+func Curvature(spline Differentiable, t float64) (float64, error) {
+	ds, err := derivs(spline, t, 2)
+	if err != nil {
+		return 0, err
+	}
+	v, a := ds[0], ds[1]
+	// |v x a| / |v|^3, generalized to arbitrary dimensions by |v x a|^2 = |v|^2 |a|^2 - (v.a)^2
+	vl := v.Len()
+	cross2 := v.Dot(v)*a.Dot(a) - v.Dot(a)*v.Dot(a)
+	return math.Sqrt(math.Max(cross2, 0)) / (vl * vl * vl), nil
+}
+
+// SignedCurvature calculates the curvature at t for 2-dimensional splines, positive if the curve turns left
+func SignedCurvature(spline Differentiable, t float64) (float64, error) {
+	ds, err := derivs(spline, t, 2)
+	if err != nil {
+		return 0, err
+	}
+	v, a := ds[0], ds[1]
+	if v.Dim() != 2 {
+		return 0, errors.New("signed curvature requires dimension 2")
+	}
+	vl := v.Len()
+	return (v[0]*a[1] - v[1]*a[0]) / (vl * vl * vl), nil
+}
+
+// Torsion calculates the torsion at t for 3-dimensional splines
+func Torsion(spline Differentiable, t float64) (float64, error) {
+	ds, err := derivs(spline, t, 3)
+	if err != nil {
+		return 0, err
+	}
+	v, a, j := ds[0], ds[1], ds[2]
+	if v.Dim() != 3 {
+		return 0, errors.New("torsion requires dimension 3")
+	}
+	va := v.Cross(a)
+	va2 := va.Dot(va)
+	if math.Sqrt(va2) <= geometryEps {
+		return 0, fmt.Errorf("curvature vanishes at %v, torsion is undefined", t)
+	}
+	return va.Dot(j) / va2, nil
+}
@@ -0,0 +1,92 @@
+package bendigo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+// helix is an analytically differentiable spline (x, y, z) = (r*cos(t), r*sin(t), h*t)
+type helix struct {
+	r, h float64
+}
+
+func (hx helix) Knots() Knots {
+	return NewUniformKnots(7)
+}
+
+func (hx helix) At(t float64) Vec {
+	return hx.Deriv(t, 0)
+}
+
+func (hx helix) Deriv(t float64, order int) Vec {
+	c, s := math.Cos(t), math.Sin(t)
+	switch order {
+	case 0:
+		return NewVec(hx.r*c, hx.r*s, hx.h*t)
+	case 1:
+		return NewVec(-hx.r*s, hx.r*c, hx.h)
+	case 2:
+		return NewVec(-hx.r*c, -hx.r*s, 0)
+	default:
+		return NewVec(hx.r*s, -hx.r*c, 0)
+	}
+}
+
+// circle2d is a counterclockwise circle of radius r in 2 dimensions
+type circle2d struct {
+	r float64
+}
+
+func (cc circle2d) Knots() Knots {
+	return NewUniformKnots(7)
+}
+
+func (cc circle2d) At(t float64) Vec {
+	return cc.Deriv(t, 0)
+}
+
+func (cc circle2d) Deriv(t float64, order int) Vec {
+	hx := helix{r: cc.r}.Deriv(t, order)
+	return hx[:2]
+}
+
+func TestCurvature(t *testing.T) {
+	for _, r := range []float64{0.5, 1, 3} {
+		k, err := Curvature(circle2d{r}, 1)
+		assert.Nil(t, err)
+		assert.InDeltaf(t, 1/r, k, delta, "curvature of circle with radius %v", r)
+		k, _ = SignedCurvature(circle2d{r}, 1)
+		assert.InDeltaf(t, 1/r, k, delta, "counterclockwise circle turns left")
+	}
+
+	hx := helix{r: 2, h: 1}
+	k, err := Curvature(hx, 0.7)
+	assert.Nil(t, err)
+	assert.InDelta(t, hx.r/(hx.r*hx.r+hx.h*hx.h), k, delta, "curvature of helix")
+	_, err = SignedCurvature(hx, 0.7)
+	assert.NotNil(t, err, "signed curvature is only defined in 2D")
+}
+
+func TestTorsion(t *testing.T) {
+	hx := helix{r: 2, h: 1}
+	tau, err := Torsion(hx, 0.7)
+	assert.Nil(t, err)
+	assert.InDelta(t, hx.h/(hx.r*hx.r+hx.h*hx.h), tau, delta, "torsion of helix")
+	_, err = Torsion(circle2d{1}, 0.7)
+	assert.NotNil(t, err, "torsion is only defined in 3D")
+}
+
+func TestFrenet(t *testing.T) {
+	hx := helix{r: 2, h: 1}
+	frame, err := Frenet(hx, 0)
+	assert.Nil(t, err)
+	l := math.Sqrt(5)
+	assert.InDeltaSlice(t, NewVec(0, 2/l, 1/l), frame.Tangent, delta, "tangent of helix")
+	assert.InDeltaSlice(t, NewVec(-1, 0, 0), frame.Normal, delta, "normal of helix points to axis")
+	assert.InDeltaSlice(t, NewVec(0, -1/l, 2/l), frame.Binormal, delta, "binormal of helix")
+
+	frame, err = Frenet(circle2d{1}, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, frame.Binormal, "no binormal in 2D")
+}
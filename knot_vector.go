@@ -0,0 +1,46 @@
+package bendigo
+
+// ClampedKnotVector extends the domain knots of a b-spline of given degree by repeating the end knots degree times,
+// so that the spline interpolates its first and last control
+func ClampedKnotVector(degree int, tknots []float64) []float64 {
+	knotVector := make([]float64, 0, len(tknots)+2*degree)
+	for i := 0; i < degree; i++ {
+		knotVector = append(knotVector, tknots[0])
+	}
+	knotVector = append(knotVector, tknots...)
+	for i := 0; i < degree; i++ {
+		knotVector = append(knotVector, tknots[len(tknots)-1])
+	}
+	return knotVector
+}
+
+// KnotSpan returns the index k of the non-empty knot span [knotVector[k], knotVector[k+1]) of the domain
+// containing t. Parameters at (or beyond) the end of the domain are mapped to the last non-empty span
+func KnotSpan(degree int, knotVector []float64, t float64) int {
+	n := len(knotVector) - degree - 1
+	for k := n - 1; k > degree; k-- {
+		if knotVector[k] <= t && knotVector[k] < knotVector[k+1] {
+			return k
+		}
+	}
+	return degree
+}
+
+// BasisFuncs evaluates the degree+1 non-vanishing b-spline basis functions of the controls span-degree ... span at t,
+// see "The NURBS Book" - A2.2 (Piegl, Tiller)
+func BasisFuncs(degree int, knotVector []float64, span int, t float64) []float64 {
+	basis := make([]float64, degree+1)
+	left, right := make([]float64, degree+1), make([]float64, degree+1)
+	basis[0] = 1
+	for j := 1; j <= degree; j++ {
+		left[j], right[j] = t-knotVector[span+1-j], knotVector[span+j]-t
+		saved := 0.
+		for r := 0; r < j; r++ {
+			tmp := basis[r] / (right[r+1] + left[j-r])
+			basis[r] = saved + right[r+1]*tmp
+			saved = left[j-r] * tmp
+		}
+		basis[j] = saved
+	}
+	return basis
+}
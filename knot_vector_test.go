@@ -0,0 +1,39 @@
+package bendigo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClampedKnotVector(t *testing.T) {
+	assert.Equal(t, []float64{0, 0, 0, 0, 1, 3, 3, 3, 3}, ClampedKnotVector(3, []float64{0, 1, 3}))
+	assert.Equal(t, []float64{2, 2, 5, 5}, ClampedKnotVector(1, []float64{2, 5}))
+}
+
+func TestKnotSpan(t *testing.T) {
+	knotVector := []float64{0, 0, 0, 1, 1, 2, 2, 2}
+	assert.Equal(t, 2, KnotSpan(2, knotVector, 0), "start of domain")
+	assert.Equal(t, 2, KnotSpan(2, knotVector, 0.5), "first span")
+	assert.Equal(t, 4, KnotSpan(2, knotVector, 1), "repeated knot belongs to the following non-empty span")
+	assert.Equal(t, 4, KnotSpan(2, knotVector, 2), "end of domain belongs to the last span")
+	assert.Equal(t, 2, KnotSpan(2, knotVector, -1), "before the domain")
+}
+
+func TestBasisFuncs(t *testing.T) {
+	degree := 3
+	knotVector := ClampedKnotVector(degree, []float64{0, 0.5, 2, 3})
+	for i := 0; i <= 30; i++ {
+		at := 3 * float64(i) / 30
+		span := KnotSpan(degree, knotVector, at)
+		assert.True(t, knotVector[span] <= at && (at < knotVector[span+1] || at == 3), "t in span")
+		basis := BasisFuncs(degree, knotVector, span, at)
+		assert.Len(t, basis, degree+1)
+		sum := 0.
+		for _, nj := range basis {
+			assert.GreaterOrEqual(t, nj, -delta)
+			sum += nj
+		}
+		assert.InDelta(t, 1, sum, delta, "partition of unity")
+	}
+	assert.InDeltaSlice(t, []float64{1, 0, 0, 0}, BasisFuncs(degree, knotVector, 3, 0), delta, "clamped start")
+}
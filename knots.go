@@ -153,7 +153,11 @@ func (k *NonUniformKnots) IsUniform() bool {
 }
 
 func (k *NonUniformKnots) Tstart() float64 {
-	return 0
+	if len(k.tknots) == 0 {
+		return 0
+	} else {
+		return k.tknots[0]
+	}
 }
 
 func (k *NonUniformKnots) Tend() float64 {
@@ -300,3 +304,13 @@ func SegmentsAroundKnot(knots Knots, knotNo int, inclBefore bool, inclAfter bool
 		return
 	}
 }
+
+// AlphaKnots derives non-uniform knots from the distances of consecutive locations, starting at 0:
+// t[i+1] = t[i] + |locs[i+1] - locs[i]|^alpha. alpha = 0 is uniform, 0.5 centripetal and 1 chordal parameterization
+func AlphaKnots(locs []Vec, alpha float64) []float64 {
+	tknots := make([]float64, len(locs))
+	for i := 1; i < len(locs); i++ {
+		tknots[i] = tknots[i-1] + math.Pow(locs[i].Sub(locs[i-1]).Len(), alpha)
+	}
+	return tknots
+}
@@ -92,6 +92,18 @@ func TestNonUniformKnots(t *testing.T) {
 	assert.InDeltaf(t, 1., u, delta, "segment-local u must be %v", 1.)
 }
 
+func TestNonUniformKnots_NonZeroStart(t *testing.T) {
+	knots := NewNonUniformKnots([]float64{2, 3, 5})
+	assert.Equal(t, 2., knots.Tstart(), "T must start at first knot")
+	assert.Equal(t, 5., knots.Tend(), "T must end at last knot")
+	segmentNo, u, err := knots.MapToSegment(2.5)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, segmentNo, "must be mapped to segment-no 0")
+	assert.InDeltaf(t, 0.5, u, delta, "segment-local u must be %v", 0.5)
+	_, _, err = knots.MapToSegment(1)
+	assert.NotNil(t, err, "before first knot")
+}
+
 func TestAdjacentSegments(t *testing.T) {
 	knots := NewUniformKnots(4)
 	fromSegmentNo, toSegmentNo, err := SegmentsAroundKnot(knots, 2, true, true)
@@ -115,3 +127,11 @@ func TestAdjacentSegments(t *testing.T) {
 	_, _, err = SegmentsAroundKnot(singleKnots, 0, true, true)
 	assert.NotNil(t, err, "SegmentsAroundKnot don't exist, error must be not-nil")
 }
+
+func TestAlphaKnots(t *testing.T) {
+	locs := []Vec{NewVec(0, 0), NewVec(3, 4), NewVec(3, 5), NewVec(3, 5)}
+	assert.Equal(t, []float64{0, 1, 2, 3}, AlphaKnots(locs, 0), "uniform")
+	assert.InDeltaSlice(t, []float64{0, 5, 6, 6}, AlphaKnots(locs, 1), delta, "chordal")
+	assert.InDeltaSlice(t, []float64{0, 2.2360679775, 3.2360679775, 3.2360679775}, AlphaKnots(locs, 0.5), delta, "centripetal")
+	assert.Empty(t, AlphaKnots(nil, 0.5))
+}
@@ -1,5 +1,7 @@
 package bendigo
 
+import "fmt"
+
 // LinaxSpline is a linearly approximated spline consisting of consecutive line segments
 type LinaxSpline struct {
 	knots Knots
@@ -28,8 +30,67 @@ func (sp LinaxSpline) At(t float64) Vec {
 	// TODO improve using binary sort
 	for _, line := range sp.lines {
 		if t >= line.Tstart && t <= line.Tend {
+			if line.Tend == line.Tstart {
+				return line.Pstart
+			}
 			fac := (t - line.Tstart) / (line.Tend - line.Tstart)
-			return line.Pend.Sub(line.Pstart).Scale(fac)
+			return line.Pstart.Add(line.Pend.Sub(line.Pstart).Scale(fac))
+		}
+	}
+	return nil
+}
+
+func (sp LinaxSpline) Dim() int {
+	if len(sp.lines) == 0 {
+		return 0
+	}
+	return sp.lines[0].Pstart.Dim()
+}
+
+func (sp LinaxSpline) AtInto(ts []float64, dst []float64) (err error) {
+	dim := sp.Dim()
+	if len(dst) < len(ts)*dim {
+		return fmt.Errorf("destination has length %v, but %v values are required", len(dst), len(ts)*dim)
+	}
+
+	lineNo := 0 // cursor, lines are ordered by parameter
+	for i, t := range ts {
+		for lineNo < len(sp.lines)-1 && t > sp.lines[lineNo].Tend {
+			lineNo++
+		}
+		for lineNo > 0 && t < sp.lines[lineNo].Tstart {
+			lineNo--
+		}
+		if len(sp.lines) == 0 || t < sp.lines[lineNo].Tstart || t > sp.lines[lineNo].Tend {
+			return fmt.Errorf("spline can't be evaluated at %v", t)
+		}
+
+		line := &sp.lines[lineNo]
+		fac := 0.
+		if line.Tend != line.Tstart {
+			fac = (t - line.Tstart) / (line.Tend - line.Tstart)
+		}
+		for d := 0; d < dim; d++ {
+			dst[i*dim+d] = line.Pstart[d] + fac*(line.Pend[d]-line.Pstart[d])
+		}
+	}
+	return nil
+}
+
+// Deriv calculates the derivative of given order, which is piecewise constant for order 1 and zero for higher orders
+func (sp LinaxSpline) Deriv(t float64, order int) Vec {
+	if order < 0 {
+		return nil
+	}
+	if order == 0 {
+		return sp.At(t)
+	}
+	for _, line := range sp.lines {
+		if t >= line.Tstart && t <= line.Tend {
+			if order > 1 || line.Tend == line.Tstart {
+				return NewZeroVec(line.Pstart.Dim())
+			}
+			return line.Pend.Sub(line.Pstart).Scale(1 / (line.Tend - line.Tstart))
 		}
 	}
 	return nil
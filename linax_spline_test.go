@@ -0,0 +1,55 @@
+package bendigo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func createLinaxSpline00to11to31() *LinaxSpline {
+	return NewLinaxSpline(NewUniformKnots(3), []Line{
+		{0, 0, 1, NewVec(0, 0), NewVec(1, 1)},
+		{1, 1, 2, NewVec(1, 1), NewVec(3, 1)},
+	})
+}
+
+func TestLinaxSpline_At(t *testing.T) {
+	linax := createLinaxSpline00to11to31()
+	assert.InDeltaSlice(t, NewVec(0.5, 0.5), linax.At(0.5), delta, "middle of first line")
+	assert.InDeltaSlice(t, NewVec(2, 1), linax.At(1.5), delta, "middle of second line")
+	assert.InDeltaSlice(t, NewVec(3, 1), linax.At(2), delta, "end of second line")
+	assert.Nil(t, linax.At(2.5), "out of domain")
+}
+
+// regression: At interpolated the difference of the line's end points instead of starting at Pstart
+func TestLinaxSpline_At_LineOffsets(t *testing.T) {
+	linax := NewLinaxSpline(NewNonUniformKnots([]float64{0, 1, 1, 2}), []Line{
+		{0, 0, 1, NewVec(2, 3), NewVec(4, 3)},
+		{1, 1, 1, NewVec(4, 3), NewVec(4, 3)},
+		{2, 1, 2, NewVec(4, 3), NewVec(4, 5)},
+	})
+	assert.InDeltaSlice(t, NewVec(2, 3), linax.At(0), delta, "start of first line")
+	assert.InDeltaSlice(t, NewVec(3, 3), linax.At(0.5), delta, "offset by start of first line")
+	assert.InDeltaSlice(t, NewVec(4, 3), linax.At(1), delta, "zero-length line in between")
+	assert.InDeltaSlice(t, NewVec(4, 4), linax.At(1.5), delta, "offset by start of last line")
+}
+
+func TestLinaxSpline_Deriv(t *testing.T) {
+	linax := createLinaxSpline00to11to31()
+	assert.InDeltaSlice(t, NewVec(1, 1), linax.Deriv(0.5, 1), delta, "velocity on first line")
+	assert.InDeltaSlice(t, NewVec(2, 0), linax.Deriv(1.5, 1), delta, "velocity on second line")
+	assert.InDeltaSlice(t, NewVec(0, 0), linax.Deriv(1.5, 2), delta, "acceleration is zero")
+	assert.InDeltaSlice(t, linax.At(1.5), linax.Deriv(1.5, 0), delta, "derivative of order 0 equals At")
+}
+
+func TestLinaxSpline_AtInto(t *testing.T) {
+	linax := createLinaxSpline00to11to31()
+	ts := []float64{0, 0.5, 1, 1.5, 2, 0.25}
+	dst := make([]float64, len(ts)*linax.Dim())
+	err := linax.AtInto(ts, dst)
+	assert.Nil(t, err)
+	for i, atT := range ts {
+		assert.InDeltaSlice(t, linax.At(atT), dst[i*2:i*2+2], delta, "batch must match At(%v)", atT)
+	}
+	assert.NotNil(t, linax.AtInto(ts, dst[:3]), "destination too short")
+	assert.NotNil(t, linax.AtInto([]float64{2.5}, dst), "out of domain")
+}
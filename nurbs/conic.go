@@ -0,0 +1,29 @@
+package nurbs
+
+import (
+	"github.com/walpod/bendigo"
+	"math"
+)
+
+// NewConic creates a quadratic rational bezier arc from start to end with given control and weight of the control:
+// weight < 1 is an elliptic, = 1 a parabolic and > 1 a hyperbolic arc
+func NewConic(start, control, end bendigo.Vec, weight float64) *NurbsVertBuilder {
+	return NewClampedNurbsVertBuilder(2, nil,
+		NewWeightedVertex(start, 1), NewWeightedVertex(control, weight), NewWeightedVertex(end, 1))
+}
+
+// NewCircle creates an exact circle of dimension 2 from 4 quarter arcs, counterclockwise starting at
+// center + (radius, 0). The quarters are the segments of the uniform domain 0 ... 4
+func NewCircle(center bendigo.Vec, radius float64) *NurbsVertBuilder {
+	w := math.Sqrt2 / 2
+	corners := [][2]float64{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}, {1, 0}}
+	vertices := make([]*WeightedVertex, len(corners))
+	for i, c := range corners {
+		weight := 1.
+		if i%2 == 1 {
+			weight = w
+		}
+		vertices[i] = NewWeightedVertex(bendigo.NewVec(center[0]+radius*c[0], center[1]+radius*c[1]), weight)
+	}
+	return NewClampedNurbsVertBuilder(2, []float64{0, 1, 1, 2, 2, 3, 3, 4}, vertices...)
+}
@@ -0,0 +1,266 @@
+package nurbs
+
+import (
+	"fmt"
+	"github.com/walpod/bendigo"
+)
+
+// NurbsVertBuilder builds non-uniform rational b-splines of arbitrary degree from weighted control vertices.
+// The knot vector has len(vertices) + degree + 1 knots, the spline is defined on the domain
+// knotVector[degree] ... knotVector[len(vertices)]
+type NurbsVertBuilder struct {
+	degree     int
+	knotVector []float64
+	vertices   []*WeightedVertex
+}
+
+// NewNurbsVertBuilder creates a nurbs builder with an arbitrary non-decreasing knot vector
+func NewNurbsVertBuilder(degree int, knotVector []float64, vertices ...*WeightedVertex) *NurbsVertBuilder {
+	if degree < 1 {
+		panic("degree must be at least 1")
+	}
+	if len(knotVector) != len(vertices)+degree+1 {
+		panic("knot vector must have length of vertices + degree + 1")
+	}
+	return &NurbsVertBuilder{degree: degree, knotVector: knotVector, vertices: vertices}
+}
+
+// NewClampedNurbsVertBuilder creates a nurbs builder interpolating the first and last vertex,
+// tknots: nil = uniform, else the domain knots (len(vertices) - degree + 1)
+func NewClampedNurbsVertBuilder(degree int, tknots []float64, vertices ...*WeightedVertex) *NurbsVertBuilder {
+	if tknots == nil {
+		tknots = make([]float64, len(vertices)-degree+1)
+		for i := range tknots {
+			tknots[i] = float64(i)
+		}
+	} else if len(tknots) != len(vertices)-degree+1 {
+		panic("tknots must have length of vertices - degree + 1")
+	}
+	return NewNurbsVertBuilder(degree, bendigo.ClampedKnotVector(degree, tknots), vertices...)
+}
+
+func (sb *NurbsVertBuilder) Degree() int {
+	return sb.degree
+}
+
+// KnotVector returns a copy of the full knot vector
+func (sb *NurbsVertBuilder) KnotVector() []float64 {
+	knotVector := make([]float64, len(sb.knotVector))
+	copy(knotVector, sb.knotVector)
+	return knotVector
+}
+
+// Knots returns the distinct knots of the domain, i.e. each segment is a non-empty knot span
+func (sb *NurbsVertBuilder) Knots() bendigo.Knots {
+	tknots := make([]float64, 0)
+	for k := sb.degree; k <= len(sb.vertices); k++ {
+		if len(tknots) == 0 || sb.knotVector[k] > tknots[len(tknots)-1] {
+			tknots = append(tknots, sb.knotVector[k])
+		}
+	}
+	return bendigo.NewNonUniformKnots(tknots)
+}
+
+func (sb *NurbsVertBuilder) Dim() int {
+	if len(sb.vertices) == 0 {
+		return 0
+	} else {
+		return sb.vertices[0].loc.Dim()
+	}
+}
+
+// Vertex returns the control vertex with given no.
+func (sb *NurbsVertBuilder) Vertex(vertexNo int) bendigo.Vertex {
+	if vertexNo < 0 || vertexNo >= len(sb.vertices) {
+		return nil
+	} else {
+		return sb.vertices[vertexNo]
+	}
+}
+
+// UpdateVertex replaces the control vertex with given no., the knot vector is unchanged
+func (sb *NurbsVertBuilder) UpdateVertex(vertexNo int, vertex bendigo.Vertex) (err error) {
+	if vertexNo < 0 || vertexNo >= len(sb.vertices) {
+		return fmt.Errorf("vertex no. %v does not exist", vertexNo)
+	}
+	sb.vertices[vertexNo] = vertex.(*WeightedVertex)
+	return nil
+}
+
+// deBoor evaluates the spline in homogeneous coordinates at t on knot span k using De Boor's algorithm
+func deBoor(degree int, knotVector []float64, hcontrols []bendigo.Vec, k int, t float64) bendigo.Vec {
+	p := degree
+	d := make([]bendigo.Vec, p+1)
+	copy(d, hcontrols[k-p:k+1])
+	for r := 1; r <= p; r++ {
+		for j := p; j >= r; j-- {
+			tlo, thi := knotVector[j+k-p], knotVector[j+1+k-r]
+			alpha := 0.
+			if thi != tlo {
+				alpha = (t - tlo) / (thi - tlo)
+			}
+			d[j] = d[j-1].Scale(1 - alpha).Add(d[j].Scale(alpha))
+		}
+	}
+	return d[p]
+}
+
+func (sb *NurbsVertBuilder) hcontrols() []bendigo.Vec {
+	hcontrols := make([]bendigo.Vec, len(sb.vertices))
+	for i, wv := range sb.vertices {
+		hcontrols[i] = wv.homogeneous()
+	}
+	return hcontrols
+}
+
+// multiplicity returns the number of occurrences of t in the knot vector
+func (sb *NurbsVertBuilder) multiplicity(t float64) int {
+	mult := 0
+	for _, kt := range sb.knotVector {
+		if kt == t {
+			mult++
+		}
+	}
+	return mult
+}
+
+// InsertKnot inserts knot t times into the knot vector using Boehm's algorithm, the shape of the spline is unchanged.
+// The multiplicity of a knot can't exceed the degree
+func (sb *NurbsVertBuilder) InsertKnot(t float64, times int) (err error) {
+	p := sb.degree
+	n := len(sb.vertices)
+	if n <= p || t < sb.knotVector[p] || t > sb.knotVector[n] {
+		return fmt.Errorf("knot %v is not within the domain", t)
+	}
+	if sb.multiplicity(t)+times > p {
+		return fmt.Errorf("multiplicity of knot %v would exceed degree %v", t, p)
+	}
+
+	for ; times > 0; times-- {
+		k := bendigo.KnotSpan(p, sb.knotVector, t)
+		hcontrols := sb.hcontrols()
+		vertices := make([]*WeightedVertex, 0, len(sb.vertices)+1)
+		vertices = append(vertices, sb.vertices[:k-p+1]...)
+		for i := k - p + 1; i <= k; i++ {
+			alpha := (t - sb.knotVector[i]) / (sb.knotVector[i+p] - sb.knotVector[i])
+			vertices = append(vertices, fromHomogeneous(hcontrols[i-1].Scale(1-alpha).Add(hcontrols[i].Scale(alpha))))
+		}
+		vertices = append(vertices, sb.vertices[k:]...)
+		sb.vertices = vertices
+
+		sb.knotVector = append(sb.knotVector, 0)
+		copy(sb.knotVector[k+2:], sb.knotVector[k+1:])
+		sb.knotVector[k+1] = t
+	}
+	return nil
+}
+
+// Beziers decomposes the spline into rational bezier curves, one for each segment, by inserting
+// all domain knots up to multiplicity degree
+func (sb *NurbsVertBuilder) Beziers() []*RationalBezier {
+	knots := sb.Knots()
+	if knots.SegmentCnt() == 0 {
+		return nil
+	}
+
+	// decompose a copy
+	dsb := NewNurbsVertBuilder(sb.degree, sb.KnotVector(), append([]*WeightedVertex(nil), sb.vertices...)...)
+	for _, t := range knots.External() {
+		if mult := dsb.multiplicity(t); mult < dsb.degree {
+			_ = dsb.InsertKnot(t, dsb.degree-mult)
+		}
+	}
+
+	p := dsb.degree
+	beziers := make([]*RationalBezier, 0, knots.SegmentCnt())
+	for k := p; k < len(dsb.vertices); k++ {
+		if dsb.knotVector[k] < dsb.knotVector[k+1] {
+			beziers = append(beziers, NewRationalBezier(dsb.vertices[k-p:k+1]...))
+		}
+	}
+	return beziers
+}
+
+func (sb *NurbsVertBuilder) Spline() bendigo.Spline {
+	return sb.NurbsSpline()
+}
+
+func (sb *NurbsVertBuilder) NurbsSpline() *NurbsSpline {
+	return NewNurbsSpline(sb.degree, sb.KnotVector(), sb.vertices...)
+}
+
+func (sb *NurbsVertBuilder) LinApproximate(fromSegmentNo, toSegmentNo int, consumer bendigo.LineConsumer, linaxParams *bendigo.LinaxParams) {
+	knots := sb.Knots()
+	beziers := sb.Beziers()
+	for segmentNo := fromSegmentNo; segmentNo <= toSegmentNo; segmentNo++ {
+		tstart, tend, err := bendigo.SegmentTrange(knots, segmentNo)
+		if err == nil { // ignore nonexistent segments
+			beziers[segmentNo].linApproximate(segmentNo, tstart, tend, consumer, linaxParams)
+		}
+	}
+}
+
+func (sb *NurbsVertBuilder) LinaxSpline(linaxParams *bendigo.LinaxParams) *bendigo.LinaxSpline {
+	return bendigo.BuildLinaxSpline(sb, linaxParams)
+}
+
+// NurbsSpline is a non-uniform rational b-spline evaluated by De Boor's algorithm in homogeneous coordinates
+type NurbsSpline struct {
+	knots      bendigo.Knots
+	degree     int
+	knotVector []float64
+	hcontrols  []bendigo.Vec
+	spans      []int             // knot span of each segment
+	beziers    []*RationalBezier // bezier decomposition, used for derivatives
+}
+
+func NewNurbsSpline(degree int, knotVector []float64, vertices ...*WeightedVertex) *NurbsSpline {
+	sb := NewNurbsVertBuilder(degree, knotVector, vertices...)
+	spans := make([]int, 0)
+	for k := degree; k < len(vertices); k++ {
+		if knotVector[k] < knotVector[k+1] {
+			spans = append(spans, k)
+		}
+	}
+	return &NurbsSpline{knots: sb.Knots(), degree: degree, knotVector: knotVector, hcontrols: sb.hcontrols(),
+		spans: spans, beziers: sb.Beziers()}
+}
+
+func (sp *NurbsSpline) Knots() bendigo.Knots {
+	return sp.knots
+}
+
+func (sp *NurbsSpline) Degree() int {
+	return sp.degree
+}
+
+func (sp *NurbsSpline) Dim() int {
+	if len(sp.hcontrols) == 0 {
+		return 0
+	}
+	return sp.hcontrols[0].Dim() - 1
+}
+
+func (sp *NurbsSpline) At(t float64) bendigo.Vec {
+	if len(sp.spans) == 0 {
+		return nil
+	}
+
+	segmentNo, _, err := sp.knots.MapToSegment(t)
+	if err != nil {
+		return nil
+	}
+	return project(deBoor(sp.degree, sp.knotVector, sp.hcontrols, sp.spans[segmentNo], t))
+}
+
+func (sp *NurbsSpline) Deriv(t float64, order int) bendigo.Vec {
+	if len(sp.spans) == 0 || order < 0 {
+		return nil
+	}
+
+	segmentNo, u, err := sp.knots.MapToSegment(t)
+	if err != nil {
+		return nil
+	}
+	return sp.beziers[segmentNo].Deriv(u, order).Scale(bendigo.DerivScale(sp.knots, segmentNo, order))
+}
@@ -0,0 +1,170 @@
+package nurbs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/walpod/bendigo"
+	"github.com/walpod/bendigo/bendigotest"
+	"github.com/walpod/bendigo/cubic"
+	"math"
+	"testing"
+)
+
+func createUnclampedNurbs() *NurbsVertBuilder {
+	return NewNurbsVertBuilder(3, []float64{0, 1, 2, 3, 3.5, 5, 6, 7, 9},
+		NewWeightedVertex(bendigo.NewVec(0, 0), 1),
+		NewWeightedVertex(bendigo.NewVec(1, 2), 2),
+		NewWeightedVertex(bendigo.NewVec(3, 2), 0.5),
+		NewWeightedVertex(bendigo.NewVec(4, 0), 1),
+		NewWeightedVertex(bendigo.NewVec(6, 1), 3),
+	)
+}
+
+func TestNurbsVertBuilder_Knots(t *testing.T) {
+	sb := createUnclampedNurbs()
+	assert.Equal(t, []float64{3, 3.5, 5}, sb.Knots().External())
+	assert.Equal(t, 2, sb.Dim())
+
+	sb = NewNurbsVertBuilder(2, []float64{0, 0, 0, 1, 1, 2, 2, 2},
+		NewWeightedVertex(bendigo.NewVec(0, 0), 1),
+		NewWeightedVertex(bendigo.NewVec(1, 1), 1),
+		NewWeightedVertex(bendigo.NewVec(2, 0), 1),
+		NewWeightedVertex(bendigo.NewVec(3, 1), 1),
+		NewWeightedVertex(bendigo.NewVec(4, 0), 1),
+	)
+	assert.Equal(t, []float64{0, 1, 2}, sb.Knots().External(), "multiple knots form a single knot")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(2, 0), sb.Spline().At(1), "double knot in degree 2 interpolates the vertex")
+}
+
+func TestNurbsSpline_NonRational(t *testing.T) {
+	// unit weights give a polynomial b-spline
+	vertices := []bendigo.Vec{bendigo.NewVec(0, 0), bendigo.NewVec(1, 2), bendigo.NewVec(3, 2), bendigo.NewVec(4, 0), bendigo.NewVec(6, 1)}
+	wvs := make([]*WeightedVertex, len(vertices))
+	cvs := make([]*cubic.ControlVertex, len(vertices))
+	for i, v := range vertices {
+		wvs[i] = NewWeightedVertex(v, 1)
+		cvs[i] = cubic.NewControlVertex(v)
+	}
+	bendigotest.AssertSplinesEqual(t, NewClampedNurbsVertBuilder(3, []float64{0, 1, 3}, wvs...).Spline(),
+		cubic.NewBSplineVertBuilder([]float64{0, 1, 3}, true, cvs...).Spline(), 30)
+}
+
+func TestNewCircle(t *testing.T) {
+	center, r := bendigo.NewVec(1, -2), 3.
+	sb := NewCircle(center, r)
+	spline := sb.NurbsSpline()
+	assert.Equal(t, 4, spline.Knots().SegmentCnt())
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(4, -2), spline.At(0), "start")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(1, 1), spline.At(1), "quarter")
+	bendigotest.AssertVecInDelta(t, bendigo.NewVec(4, -2), spline.At(4), "end")
+
+	for i := 0; i <= 40; i++ {
+		at := float64(i) / 10
+		assert.InDeltaf(t, r, spline.At(at).Sub(center).Len(), bendigotest.Delta, "point at %v is on circle", at)
+		k, err := bendigo.SignedCurvature(spline, at)
+		assert.Nil(t, err)
+		assert.InDeltaf(t, 1/r, k, 1e-9, "curvature at %v", at)
+	}
+}
+
+func TestNewConic(t *testing.T) {
+	start, control, end := bendigo.NewVec(0, 0), bendigo.NewVec(1, 2), bendigo.NewVec(2, 0)
+
+	// weight 1 is a polynomial parabola
+	parabola := NewConic(start, control, end, 1).Spline()
+	for i := 0; i <= 10; i++ {
+		u := float64(i) / 10
+		expected := start.Scale((1 - u) * (1 - u)).Add(control.Scale(2 * u * (1 - u))).Add(end.Scale(u * u))
+		bendigotest.AssertVecInDelta(t, expected, parabola.At(u), "parabola")
+	}
+
+	// higher weights pull the curve towards the control
+	mid := func(weight float64) float64 { return NewConic(start, control, end, weight).Spline().At(0.5)[1] }
+	assert.Less(t, mid(0.5), mid(1))
+	assert.Less(t, mid(1), mid(2))
+}
+
+func TestNurbsVertBuilder_InsertKnot(t *testing.T) {
+	sb := createUnclampedNurbs()
+	before := sb.NurbsSpline()
+	assert.Nil(t, sb.InsertKnot(4, 2))
+	assert.Equal(t, []float64{0, 1, 2, 3, 3.5, 4, 4, 5, 6, 7, 9}, sb.KnotVector())
+	assert.Equal(t, 7, len(sb.vertices))
+	assert.Equal(t, []float64{3, 3.5, 4, 5}, sb.Knots().External())
+	bendigotest.AssertSplinesEqual(t, before, sb.Spline(), 50)
+
+	assert.Nil(t, sb.InsertKnot(5, 1))
+	bendigotest.AssertSplinesEqual(t, before, sb.Spline(), 50)
+
+	assert.NotNil(t, sb.InsertKnot(4, 2), "multiplicity exceeds degree")
+	assert.NotNil(t, sb.InsertKnot(2.5, 1), "outside of domain")
+}
+
+func TestNurbsVertBuilder_Beziers(t *testing.T) {
+	for _, sb := range []*NurbsVertBuilder{createUnclampedNurbs(), NewCircle(bendigo.NewVec(0, 0), 2)} {
+		spline := sb.Spline()
+		knots := sb.Knots()
+		beziers := sb.Beziers()
+		assert.Equal(t, knots.SegmentCnt(), len(beziers))
+		for segmentNo, bez := range beziers {
+			assert.Equal(t, sb.Degree(), bez.Degree())
+			tstart, tend, _ := bendigo.SegmentTrange(knots, segmentNo)
+			for i := 0; i <= 10; i++ {
+				u := float64(i) / 10
+				bendigotest.AssertVecInDelta(t, spline.At(tstart+u*(tend-tstart)), bez.At(u), "bezier matches spline")
+			}
+		}
+	}
+}
+
+func TestNurbsSpline_Deriv(t *testing.T) {
+	spline := createUnclampedNurbs().NurbsSpline()
+	const h = 1e-5
+	for _, at := range []float64{3.1, 3.7, 4.9} {
+		numeric := spline.At(at + h).Sub(spline.At(at - h)).Scale(1 / (2 * h))
+		d1 := spline.Deriv(at, 1)
+		for d := 0; d < 2; d++ {
+			assert.InDelta(t, numeric[d], d1[d], 1e-6)
+		}
+		numeric = spline.Deriv(at+h, 1).Sub(spline.Deriv(at-h, 1)).Scale(1 / (2 * h))
+		d2 := spline.Deriv(at, 2)
+		for d := 0; d < 2; d++ {
+			assert.InDelta(t, numeric[d], d2[d], 1e-5)
+		}
+	}
+	bendigotest.AssertVecInDelta(t, spline.At(4), spline.Deriv(4, 0), "order 0")
+	assert.Nil(t, spline.Deriv(10, 1))
+}
+
+func TestNurbsVertBuilder_LinApproximate(t *testing.T) {
+	center, r, maxDist := bendigo.NewVec(0, 0), 2., 0.01
+	sb := NewCircle(center, r)
+	linax := sb.LinaxSpline(bendigo.NewLinaxParams(maxDist))
+	lines := linax.Lines()
+	assert.Greater(t, len(lines), 8)
+	for i, line := range lines {
+		assert.InDelta(t, r, line.Pstart.Sub(center).Len(), bendigotest.Delta)
+		mid := line.Pstart.Add(line.Pend).Scale(0.5)
+		assert.LessOrEqual(t, r-mid.Sub(center).Len(), maxDist)
+		if i > 0 {
+			assert.Equal(t, lines[i-1].Tend, line.Tstart)
+		}
+	}
+	assert.Equal(t, 0., lines[0].Tstart)
+	assert.Equal(t, 4., lines[len(lines)-1].Tend)
+}
+
+func TestRationalBezier_Split(t *testing.T) {
+	rb := NewRationalBezier(
+		NewWeightedVertex(bendigo.NewVec(0, 0), 1),
+		NewWeightedVertex(bendigo.NewVec(1, 1), math.Sqrt2/2),
+		NewWeightedVertex(bendigo.NewVec(2, 0), 1),
+	)
+	left, right := rb.Split(0.3)
+	for i := 0; i <= 10; i++ {
+		u := float64(i) / 10
+		bendigotest.AssertVecInDelta(t, rb.At(0.3*u), left.At(u), "left")
+		bendigotest.AssertVecInDelta(t, rb.At(0.3+0.7*u), right.At(u), "right")
+	}
+	assert.Equal(t, 1., left.Vertex(0).Weight())
+	assert.Nil(t, rb.Vertex(3))
+}
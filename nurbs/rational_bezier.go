@@ -0,0 +1,113 @@
+package nurbs
+
+import (
+	"github.com/walpod/bendigo"
+)
+
+// RationalBezier is a rational bezier curve of arbitrary degree on the local parameter range [0,1]
+type RationalBezier struct {
+	hcontrols []bendigo.Vec // controls in homogeneous coordinates
+}
+
+func NewRationalBezier(vertices ...*WeightedVertex) *RationalBezier {
+	hcontrols := make([]bendigo.Vec, len(vertices))
+	for i, wv := range vertices {
+		hcontrols[i] = wv.homogeneous()
+	}
+	return &RationalBezier{hcontrols: hcontrols}
+}
+
+func (rb *RationalBezier) Degree() int {
+	return len(rb.hcontrols) - 1
+}
+
+func (rb *RationalBezier) Dim() int {
+	if len(rb.hcontrols) == 0 {
+		return 0
+	}
+	return rb.hcontrols[0].Dim() - 1
+}
+
+// Vertex returns the control vertex with given no.
+func (rb *RationalBezier) Vertex(i int) *WeightedVertex {
+	if i < 0 || i >= len(rb.hcontrols) {
+		return nil
+	}
+	return fromHomogeneous(rb.hcontrols[i])
+}
+
+// At calculates the point at local parameter u using De Casteljau algorithm in homogeneous coordinates
+func (rb *RationalBezier) At(u float64) bendigo.Vec {
+	if len(rb.hcontrols) == 0 {
+		return nil
+	}
+	return project(bendigo.DeCasteljau(rb.hcontrols, u))
+}
+
+// Deriv calculates the derivative of given order with respect to local parameter u
+func (rb *RationalBezier) Deriv(u float64, order int) bendigo.Vec {
+	if len(rb.hcontrols) == 0 || order < 0 {
+		return nil
+	}
+
+	// derivatives of the homogeneous polynomial curve
+	hderivs := make([]bendigo.Vec, order+1)
+	hodograph := rb.hcontrols
+	for k := 0; k <= order; k++ {
+		if len(hodograph) == 0 {
+			hderivs[k] = bendigo.NewZeroVec(rb.Dim() + 1)
+		} else {
+			hderivs[k] = bendigo.DeCasteljau(hodograph, u)
+			hodograph = bendigo.HodographControls(hodograph)
+		}
+	}
+
+	// C^(k) = (A^(k) - sum_{i=1..k} binom(k,i) * w^(i) * C^(k-i)) / w
+	dim := rb.Dim()
+	w := hderivs[0][dim]
+	derivs := make([]bendigo.Vec, order+1)
+	for k := 0; k <= order; k++ {
+		c := bendigo.NewZeroVec(dim)
+		copy(c, hderivs[k][:dim])
+		binom := 1.
+		for i := 1; i <= k; i++ {
+			binom = binom * float64(k-i+1) / float64(i)
+			c = c.Sub(derivs[k-i].Scale(binom * hderivs[i][dim]))
+		}
+		derivs[k] = c.Scale(1 / w)
+	}
+	return derivs[order]
+}
+
+// Split subdivides the curve at local parameter u into two rational bezier curves
+func (rb *RationalBezier) Split(u float64) (left, right *RationalBezier) {
+	lc, rc := bendigo.SplitBezier(rb.hcontrols, u)
+	return &RationalBezier{hcontrols: lc}, &RationalBezier{hcontrols: rc}
+}
+
+// isFlat checks if all projected controls are within maxDist of the line between start and end.
+// With positive weights the curve lies within the convex hull of its projected controls
+func (rb *RationalBezier) isFlat(maxDist float64) bool {
+	controls := make([]bendigo.Vec, len(rb.hcontrols))
+	for i, hc := range rb.hcontrols {
+		controls[i] = project(hc)
+	}
+	return bendigo.IsFlatBezier(controls, maxDist)
+}
+
+// linApproximate subdivides the curve recursively until flat and passes the lines to the consumer,
+// the local parameter range [0,1] is mapped to [tstart,tend]
+func (rb *RationalBezier) linApproximate(segmentNo int, tstart, tend float64, consumer bendigo.LineConsumer, linaxParams *bendigo.LinaxParams) {
+	var subdivide func(piece *RationalBezier, ts, te float64, depth int)
+	subdivide = func(piece *RationalBezier, ts, te float64, depth int) {
+		if depth >= bendigo.MaxLinaxDepth || piece.isFlat(linaxParams.MaxDist) {
+			consumer.ConsumeLine(segmentNo, ts, te, project(piece.hcontrols[0]), project(piece.hcontrols[len(piece.hcontrols)-1]))
+		} else {
+			tm := (ts + te) / 2
+			left, right := piece.Split(0.5)
+			subdivide(left, ts, tm, depth+1)
+			subdivide(right, tm, te, depth+1)
+		}
+	}
+	subdivide(rb, tstart, tend, 0)
+}
@@ -0,0 +1,53 @@
+package nurbs
+
+import "github.com/walpod/bendigo"
+
+// WeightedVertex is a control vertex with a positive weight, higher weights pull the curve towards the vertex
+type WeightedVertex struct {
+	loc    bendigo.Vec
+	weight float64
+}
+
+func NewWeightedVertex(loc bendigo.Vec, weight float64) *WeightedVertex {
+	return &WeightedVertex{loc: loc, weight: weight}
+}
+
+func (wv *WeightedVertex) Loc() bendigo.Vec {
+	return wv.loc
+}
+
+func (wv *WeightedVertex) Weight() float64 {
+	return wv.weight
+}
+
+// WithShift creates a new WeightedVertex with the same weight, shifted (translated) in direction given by vector dv
+func (wv *WeightedVertex) WithShift(dv bendigo.Vec) *WeightedVertex {
+	return NewWeightedVertex(wv.loc.Add(dv), wv.weight)
+}
+
+// homogeneous returns the vertex in homogeneous coordinates (weight * loc, weight)
+func (wv *WeightedVertex) homogeneous() bendigo.Vec {
+	dim := wv.loc.Dim()
+	h := bendigo.NewZeroVec(dim + 1)
+	for d := 0; d < dim; d++ {
+		h[d] = wv.weight * wv.loc[d]
+	}
+	h[dim] = wv.weight
+	return h
+}
+
+// fromHomogeneous creates a weighted vertex from homogeneous coordinates
+func fromHomogeneous(h bendigo.Vec) *WeightedVertex {
+	return NewWeightedVertex(project(h), h[len(h)-1])
+}
+
+// project maps homogeneous coordinates to the location, i.e. divides by the weight
+func project(h bendigo.Vec) bendigo.Vec {
+	dim := len(h) - 1
+	w := h[dim]
+	v := bendigo.NewZeroVec(dim)
+	for d := 0; d < dim; d++ {
+		v[d] = h[d] / w
+	}
+	return v
+}
@@ -0,0 +1,44 @@
+package bendigo
+
+// Parameterization derives the knots of a spline from the locations of its vertices
+type Parameterization interface {
+	Knots(locs []Vec) Knots
+}
+
+// UniformParameterization assigns the knots 0, 1, 2, ... regardless of the locations
+type UniformParameterization struct{}
+
+func (UniformParameterization) Knots(locs []Vec) Knots {
+	return NewUniformKnots(len(locs))
+}
+
+// AlphaParameterization spaces the knots by the distances of consecutive locations raised to the power of Alpha,
+// see AlphaKnots
+type AlphaParameterization struct {
+	Alpha float64
+}
+
+func (ap AlphaParameterization) Knots(locs []Vec) Knots {
+	return NewNonUniformKnots(AlphaKnots(locs, ap.Alpha))
+}
+
+// ChordLengthParameterization spaces the knots by the distances of consecutive locations
+func ChordLengthParameterization() AlphaParameterization {
+	return AlphaParameterization{Alpha: 1}
+}
+
+// CentripetalParameterization spaces the knots by the square root of the distances of consecutive locations
+func CentripetalParameterization() AlphaParameterization {
+	return AlphaParameterization{Alpha: 0.5}
+}
+
+// FuncParameterization derives the knots by a custom function, which must return one non-decreasing knot per location
+type FuncParameterization func(locs []Vec) []float64
+
+func (fp FuncParameterization) Knots(locs []Vec) Knots {
+	tknots := fp(locs)
+	if len(tknots) != len(locs) {
+		panic("parameterization must return one knot per location")
+	}
+	return NewNonUniformKnots(tknots)
+}
@@ -0,0 +1,30 @@
+package bendigo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParameterization(t *testing.T) {
+	locs := []Vec{NewVec(0, 0), NewVec(3, 4), NewVec(3, 8)}
+
+	knots := UniformParameterization{}.Knots(locs)
+	assert.True(t, knots.IsUniform())
+	assert.Equal(t, 3, knots.KnotCnt())
+
+	knots = ChordLengthParameterization().Knots(locs)
+	assert.False(t, knots.IsUniform())
+	assert.InDeltaSlice(t, []float64{0, 5, 9}, knots.External(), delta)
+
+	knots = CentripetalParameterization().Knots(locs)
+	assert.InDeltaSlice(t, []float64{0, 2.2360679775, 4.2360679775}, knots.External(), delta)
+
+	var param Parameterization = FuncParameterization(func(locs []Vec) []float64 {
+		tknots := make([]float64, len(locs))
+		for i := range tknots {
+			tknots[i] = float64(i * i)
+		}
+		return tknots
+	})
+	assert.Equal(t, []float64{0, 1, 4}, param.Knots(locs).External())
+}
@@ -0,0 +1,44 @@
+package bendigo
+
+// SegmentCursor maps parameters to segments like Knots.MapToSegment, but starts searching at the segment found
+// by the previous call. Walking monotonically ordered parameters thus takes constant time per parameter
+type SegmentCursor struct {
+	knots        Knots
+	segmentNo    int
+	tstart, tend float64
+}
+
+func NewSegmentCursor(knots Knots) SegmentCursor {
+	sc := SegmentCursor{knots: knots, segmentNo: -1}
+	sc.moveTo(0)
+	return sc
+}
+
+func (sc *SegmentCursor) moveTo(segmentNo int) bool {
+	if !sc.knots.SegmentExists(segmentNo) {
+		return false
+	}
+	tstart, tend, _ := SegmentTrange(sc.knots, segmentNo)
+	sc.segmentNo, sc.tstart, sc.tend = segmentNo, tstart, tend
+	return true
+}
+
+// MapToSegment maps parameter t to segment and segment-local parameter u
+func (sc *SegmentCursor) MapToSegment(t float64) (segmentNo int, u float64, err error) {
+	if sc.segmentNo < 0 {
+		return sc.knots.MapToSegment(t) // yields appropriate error
+	}
+
+	for t >= sc.tend && sc.moveTo(sc.segmentNo+1) {
+	}
+	for t < sc.tstart && sc.moveTo(sc.segmentNo-1) {
+	}
+	if t < sc.tstart || t > sc.tend {
+		return sc.knots.MapToSegment(t) // outside of domain, yields appropriate error
+	}
+
+	if sc.tend == sc.tstart {
+		return sc.segmentNo, 0, nil
+	}
+	return sc.segmentNo, (t - sc.tstart) / (sc.tend - sc.tstart), nil
+}
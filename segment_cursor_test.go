@@ -0,0 +1,33 @@
+package bendigo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func TestSegmentCursor(t *testing.T) {
+	for _, knots := range []Knots{NewUniformKnots(5), NewNonUniformKnots([]float64{0, 0.5, 0.5, 2, 3.5})} {
+		cursor := NewSegmentCursor(knots)
+		rnd := rand.New(rand.NewSource(1))
+		ts := []float64{0, 0.25, 0.5, 1, 2, 3, 3.5, 4, 1.5, 0.1, 0}
+		for i := 0; i < 20; i++ {
+			ts = append(ts, rnd.Float64()*knots.Tend())
+		}
+		for _, atT := range ts {
+			expSegmentNo, expU, expErr := knots.MapToSegment(atT)
+			segmentNo, u, err := cursor.MapToSegment(atT)
+			assert.Equalf(t, expErr == nil, err == nil, "error must match at %v", atT)
+			assert.Equalf(t, expSegmentNo, segmentNo, "segment-no. must match at %v", atT)
+			assert.InDeltaf(t, expU, u, delta, "segment-local u must match at %v", atT)
+		}
+		_, _, err := cursor.MapToSegment(-1)
+		assert.NotNil(t, err, "out of domain")
+		_, _, err = cursor.MapToSegment(knots.Tend() + 1)
+		assert.NotNil(t, err, "out of domain")
+	}
+
+	cursor := NewSegmentCursor(NewUniformKnots(0))
+	_, _, err := cursor.MapToSegment(0)
+	assert.NotNil(t, err, "empty knots")
+}
@@ -1,5 +1,7 @@
 package bendigo
 
+import "math"
+
 type Spline interface {
 	Knots() Knots
 
@@ -7,6 +9,35 @@ type Spline interface {
 	At(t float64) Vec
 }
 
+// Differentiable is a Spline which additionally provides derivatives with respect to parameter t
+type Differentiable interface {
+	Spline
+
+	// Deriv calculates the derivative of given order at parameter t (1 = velocity, 2 = acceleration, 3 = jerk)
+	Deriv(t float64, order int) Vec
+}
+
+// BatchSpline is a Spline which evaluates many parameters at once, writing into caller provided buffers
+type BatchSpline interface {
+	Spline
+
+	Dim() int
+
+	// AtInto calculates the points at parameters ts and writes them consecutively into dst, which must hold
+	// len(ts) * Dim() values. Ascending (or descending) parameters are mapped most efficiently
+	AtInto(ts []float64, dst []float64) (err error)
+}
+
+// DerivScale returns the factor converting a derivative of given order with respect to the segment-local
+// parameter u into the derivative with respect to t, i.e. (1/segmentLen)^order
+func DerivScale(knots Knots, segmentNo int, order int) float64 {
+	segmentLen, err := knots.SegmentLen(segmentNo)
+	if err != nil || segmentLen == 0 {
+		return 1
+	}
+	return math.Pow(1/segmentLen, float64(order))
+}
+
 type SplineBuilder interface {
 	Knots() Knots
 
@@ -34,11 +65,14 @@ type SplineVertBuilder interface {
 	DeleteVertex(knotNo int) (err error)
 }
 
+// Vertices returns the vertices of all knots, skipping knots without vertex like the closing knot of closed splines
 func Vertices(builder SplineVertBuilder) []Vertex {
 	cnt := builder.Knots().KnotCnt()
-	vertices := make([]Vertex, cnt)
+	vertices := make([]Vertex, 0, cnt)
 	for i := 0; i < cnt; i++ {
-		vertices[i] = builder.Vertex(i)
+		if vertex := builder.Vertex(i); vertex != nil {
+			vertices = append(vertices, vertex)
+		}
 	}
 	return vertices
 }
@@ -53,6 +53,20 @@ func (v Vec) Scale(scale float64) Vec {
 	return r
 }
 
+// Dot calculates the scalar (inner) product
+func (v Vec) Dot(w Vec) float64 {
+	dp := 0.
+	for d := 0; d < len(v); d++ {
+		dp += v[d] * w[d]
+	}
+	return dp
+}
+
+// Cross calculates the cross product of two vectors of dimension 3
+func (v Vec) Cross(w Vec) Vec {
+	return Vec{v[1]*w[2] - v[2]*w[1], v[2]*w[0] - v[0]*w[2], v[0]*w[1] - v[1]*w[0]}
+}
+
 func (v Vec) Len() float64 {
 	vl := 0.
 	for d := 0; d < len(v); d++ {
@@ -68,7 +82,7 @@ func (v Vec) ProjectedVecDist(w Vec) float64 {
 	if len(v) == 2 {
 		area = math.Abs(w[0]*v[1] - w[1]*v[0])
 	} else if len(v) == 3 {
-		area = Vec{v[1]*w[2] - v[2]*w[1], v[2]*w[0] - v[0]*w[2], v[0]*w[1] - v[1]*w[0]}.Len()
+		area = v.Cross(w).Len()
 	} else {
 		// TODO implement it
 		panic("ProjectedVecDist not yet implemented for dim >= 4")